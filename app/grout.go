@@ -11,6 +11,10 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "sync-game" {
+		os.Exit(runSyncGame(os.Args[2:]))
+	}
+
 	defer cleanup()
 
 	result := setup()
@@ -32,6 +36,10 @@ func main() {
 }
 
 func cleanup() {
+	if syncScheduler != nil {
+		syncScheduler.Stop()
+	}
+
 	if autoSync != nil && autoSync.IsRunning() {
 		gaba.GetLogger().Info("Waiting for auto-sync to complete before exiting...")
 		gaba.ProcessMessage(
@@ -44,6 +52,10 @@ func cleanup() {
 		)
 	}
 
+	if backgroundDownloads != nil {
+		backgroundDownloads.Stop()
+	}
+
 	if err := os.RemoveAll(".tmp"); err != nil {
 		gaba.GetLogger().Error("Failed to clean .tmp directory", "error", err)
 	}
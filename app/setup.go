@@ -120,6 +120,10 @@ func setup() SetupResult {
 		internal.SaveConfig(config)
 	}
 
+	if config.EnsureDeviceIdentity() {
+		internal.SaveConfig(config)
+	}
+
 	if config.LogLevel != "" {
 		gaba.SetRawLogLevel(config.LogLevel)
 	}
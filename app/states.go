@@ -3,6 +3,7 @@ package main
 import (
 	"grout/cache"
 	"grout/cfw"
+	"grout/download"
 	"grout/internal"
 	"grout/internal/constants"
 	"grout/romm"
@@ -10,8 +11,10 @@ import (
 	"grout/ui"
 	"grout/update"
 	"os"
+	"slices"
 	gosync "sync"
 	"sync/atomic"
+	"time"
 
 	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
 	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
@@ -22,15 +25,23 @@ import (
 var (
 	autoSync       *sync.AutoSync
 	autoSyncOnce   gosync.Once
+	syncScheduler  *sync.SyncScheduler
 	autoUpdate     *update.AutoUpdate
 	autoUpdateOnce gosync.Once
+
+	backgroundDownloads *download.Queue
 )
 
+// subscriptionRefreshInterval is how long background syncs trust the cached collections
+// before fetching them from RomM again.
+const subscriptionRefreshInterval = 15 * time.Minute
+
 const (
 	platformSelection           gaba.StateName = "platform_selection"
 	gameList                    gaba.StateName = "game_list"
 	gameDetails                 gaba.StateName = "game_details"
 	gameOptions                 gaba.StateName = "game_options"
+	removeGames                 gaba.StateName = "remove_games"
 	collectionList              gaba.StateName = "collection_list"
 	collectionPlatformSelection gaba.StateName = "collection_platform_selection"
 	search                      gaba.StateName = "search"
@@ -38,6 +49,7 @@ const (
 	settings                    gaba.StateName = "settings"
 	generalSettings             gaba.StateName = "general_settings"
 	collectionsSettings         gaba.StateName = "collections_settings"
+	extractionSettings          gaba.StateName = "extraction_settings"
 	advancedSettings            gaba.StateName = "advanced_settings"
 	settingsPlatformMapping     gaba.StateName = "platform_mapping"
 	saveSyncSettings            gaba.StateName = "save_sync_settings"
@@ -45,6 +57,12 @@ const (
 	logoutConfirmation          gaba.StateName = "logout_confirmation"
 	refreshCache                gaba.StateName = "refresh_cache"
 	saveSync                    gaba.StateName = "save_sync"
+	restoreSaves                gaba.StateName = "restore_saves"
+	cleanUpSaves                gaba.StateName = "clean_up_saves"
+	rebuildPlaylists            gaba.StateName = "rebuild_playlists"
+	verifyGames                 gaba.StateName = "verify_games"
+	activity                    gaba.StateName = "activity"
+	downloadQueue               gaba.StateName = "download_queue"
 	biosDownload                gaba.StateName = "bios_download"
 	artworkSync                 gaba.StateName = "artwork_sync"
 	updateCheck                 gaba.StateName = "update_check"
@@ -124,6 +142,12 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 	// Validate artwork cache in background
 	cache.RunArtworkValidation()
 
+	// Resume downloads left unfinished by the last session
+	backgroundDownloads = download.NewQueue(config.Hosts[0], config)
+	backgroundDownloads.OnComplete(func(romm.Rom) { triggerAutoSync() })
+	ui.AddStatusBarIcon(backgroundDownloads.Icon())
+	backgroundDownloads.Start()
+
 	gaba.AddState(fsm, platformSelection, func(ctx *gaba.Context) (ui.PlatformSelectionOutput, gaba.ExitCode) {
 		platforms, _ := gaba.Get[[]romm.Platform](ctx)
 		nav, _ := gaba.Get[*NavState](ctx)
@@ -137,8 +161,12 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 			autoSyncOnce.Do(func() {
 				host, _ := gaba.Get[romm.Host](ctx)
 				autoSync = sync.NewAutoSync(host, config)
+				autoSync.OnComplete(syncSubscriptions)
 				ui.AddStatusBarIcon(autoSync.Icon())
 				autoSync.Start()
+
+				syncScheduler = sync.NewSyncScheduler(autoSync, config)
+				syncScheduler.Start()
 			})
 		}
 
@@ -259,6 +287,33 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 			nav.CollectionListPos = ListPosition{}
 			return nil
 		}).
+		OnWithHook(constants.ExitCodeToggleSubscription, collectionList, func(ctx *gaba.Context) error {
+			config, _ := gaba.Get[*internal.Config](ctx)
+			nav, _ := gaba.Get[*NavState](ctx)
+			output, _ := gaba.Get[ui.CollectionSelectionOutput](ctx)
+
+			// The Sync Subscriptions row comes and goes with the first subscription
+			hadSubscriptions := len(config.Subscriptions) > 0
+			config.SetSubscribed(output.SelectedCollection, !config.IsSubscribed(output.SelectedCollection))
+			if err := internal.SaveConfig(config); err != nil {
+				gaba.GetLogger().Error("Error saving subscriptions", "error", err)
+			}
+			if hasSubscriptions := len(config.Subscriptions) > 0; hasSubscriptions != hadSubscriptions && nav.CollectionSearchFilter == "" {
+				if hasSubscriptions {
+					nav.CollectionListPos.Index++
+				} else {
+					nav.CollectionListPos.Index = max(0, nav.CollectionListPos.Index-1)
+				}
+			}
+
+			ui.NewSubscriptionsScreen().Execute(config, backgroundDownloads)
+			return nil
+		}).
+		OnWithHook(constants.ExitCodeSyncSubscriptions, collectionList, func(ctx *gaba.Context) error {
+			config, _ := gaba.Get[*internal.Config](ctx)
+			ui.NewSubscriptionsScreen().Execute(config, backgroundDownloads)
+			return nil
+		}).
 		On(gaba.ExitCodeBack, platformSelection)
 
 	gaba.AddState(fsm, collectionPlatformSelection, func(ctx *gaba.Context) (ui.CollectionPlatformSelectionOutput, gaba.ExitCode) {
@@ -362,6 +417,28 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 		return result.Value, result.ExitCode
 	}).
 		On(gaba.ExitCodeSuccess, gameDetails).
+		OnWithHook(constants.ExitCodeUpdateGames, gameList, func(ctx *gaba.Context) error {
+			gameListOutput, _ := gaba.Get[ui.GameListOutput](ctx)
+			config, _ := gaba.Get[*internal.Config](ctx)
+			host, _ := gaba.Get[romm.Host](ctx)
+			nav, _ := gaba.Get[*NavState](ctx)
+
+			if ui.NewUpdateGamesScreen().Execute(gameListOutput.OutdatedGames) {
+				queueDownloads(config, host, nav, gameListOutput.Platform, gameListOutput.OutdatedGames, gameListOutput.AllGames)
+			}
+			return nil
+		}).
+		OnWithHook(constants.ExitCodeDownloadAll, gameList, func(ctx *gaba.Context) error {
+			gameListOutput, _ := gaba.Get[ui.GameListOutput](ctx)
+			config, _ := gaba.Get[*internal.Config](ctx)
+			host, _ := gaba.Get[romm.Host](ctx)
+			nav, _ := gaba.Get[*NavState](ctx)
+
+			if games, ok := ui.NewDownloadAllScreen().Execute(config, gameListOutput.Platform, gameListOutput.AllGames); ok {
+				queueDownloads(config, host, nav, gameListOutput.Platform, games, gameListOutput.AllGames)
+			}
+			return nil
+		}).
 		On(constants.ExitCodeSearch, search).
 		On(constants.ExitCodeBIOS, biosDownload).
 		OnWithHook(constants.ExitCodeClearSearch, gameList, func(ctx *gaba.Context) error {
@@ -390,13 +467,25 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 		gameListOutput, _ := gaba.Get[ui.GameListOutput](ctx)
 		nav, _ := gaba.Get[*NavState](ctx)
 
-		// If multiple games selected, skip details and go straight to download
+		// If multiple games selected, skip details and go straight to download, asking
+		// first when some could be removed from the device instead
 		if len(gameListOutput.SelectedGames) != 1 {
-			downloadScreen := ui.NewDownloadScreen()
-			downloadOutput := downloadScreen.Execute(*config, host, gameListOutput.Platform, gameListOutput.SelectedGames, gameListOutput.AllGames, nav.SearchFilter)
-			nav.CurrentGames = downloadOutput.AllGames
-			nav.SearchFilter = downloadOutput.SearchFilter
-			triggerAutoSync()
+			games := gameListOutput.SelectedGames
+			action := ui.SelectedGamesDownload
+			if slices.ContainsFunc(games, func(game romm.Rom) bool {
+				return len(download.InstalledPaths(*config, gameListOutput.Platform, game)) > 0
+			}) {
+				var ok bool
+				if action, ok = ui.NewSelectedGamesScreen().Execute(len(games)); !ok {
+					return ui.GameDetailsOutput{}, gaba.ExitCodeBack
+				}
+			}
+
+			if action == ui.SelectedGamesRemove {
+				ui.NewRemoveGamesScreen().Execute(config, host, gameListOutput.Platform, games)
+			} else {
+				queueDownloads(config, host, nav, gameListOutput.Platform, games, gameListOutput.AllGames)
+			}
 			return ui.GameDetailsOutput{}, gaba.ExitCodeBack
 		}
 
@@ -422,11 +511,7 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 			nav, _ := gaba.Get[*NavState](ctx)
 
 			if detailsOutput.DownloadRequested {
-				downloadScreen := ui.NewDownloadScreen()
-				downloadOutput := downloadScreen.Execute(*config, host, detailsOutput.Platform, []romm.Rom{detailsOutput.Game}, gameListOutput.AllGames, nav.SearchFilter)
-				nav.CurrentGames = downloadOutput.AllGames
-				nav.SearchFilter = downloadOutput.SearchFilter
-				triggerAutoSync()
+				queueDownloads(config, host, nav, detailsOutput.Platform, []romm.Rom{detailsOutput.Game}, gameListOutput.AllGames)
 			}
 
 			return nil
@@ -445,8 +530,9 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 
 		screen := ui.NewGameOptionsScreen()
 		result, err := screen.Draw(ui.GameOptionsInput{
-			Config: config,
-			Game:   gameListOutput.SelectedGames[0],
+			Config:   config,
+			Platform: gameListOutput.Platform,
+			Game:     gameListOutput.SelectedGames[0],
 		})
 
 		if err != nil {
@@ -460,8 +546,27 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 			gaba.Set(ctx, output.Config)
 			return nil
 		}).
+		OnWithHook(constants.ExitCodeRemoveGames, removeGames, func(ctx *gaba.Context) error {
+			output, _ := gaba.Get[ui.GameOptionsOutput](ctx)
+			gaba.Set(ctx, output.Config)
+			return nil
+		}).
 		On(gaba.ExitCodeBack, gameDetails)
 
+	gaba.AddState(fsm, removeGames, func(ctx *gaba.Context) (ui.RemoveGamesOutput, gaba.ExitCode) {
+		config, _ := gaba.Get[*internal.Config](ctx)
+		host, _ := gaba.Get[romm.Host](ctx)
+		gameListOutput, _ := gaba.Get[ui.GameListOutput](ctx)
+
+		output := ui.NewRemoveGamesScreen().Execute(config, host, gameListOutput.Platform, gameListOutput.SelectedGames)
+		if output.Removed == 0 {
+			return output, gaba.ExitCodeBack
+		}
+		return output, gaba.ExitCodeSuccess
+	}).
+		On(gaba.ExitCodeSuccess, gameList).
+		On(gaba.ExitCodeBack, gameOptions)
+
 	gaba.AddState(fsm, search, func(ctx *gaba.Context) (ui.SearchOutput, gaba.ExitCode) {
 		nav, _ := gaba.Get[*NavState](ctx)
 
@@ -555,9 +660,14 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 		}).
 		On(constants.ExitCodeGeneralSettings, generalSettings).
 		On(constants.ExitCodeCollectionsSettings, collectionsSettings).
+		On(constants.ExitCodeExtractionSettings, extractionSettings).
 		On(constants.ExitCodeEditMappings, settingsPlatformMapping).
 		On(constants.ExitCodeAdvancedSettings, advancedSettings).
 		On(constants.ExitCodeSaveSyncSettings, saveSyncSettings).
+		On(constants.ExitCodeRestoreSaves, restoreSaves).
+		On(constants.ExitCodeCleanUpSaves, cleanUpSaves).
+		On(constants.ExitCodeDownloadQueue, downloadQueue).
+		On(constants.ExitCodeActivity, activity).
 		On(constants.ExitCodeInfo, info).
 		On(constants.ExitCodeCheckUpdate, updateCheck).
 		OnWithHook(gaba.ExitCodeBack, platformSelection, func(ctx *gaba.Context) error {
@@ -611,6 +721,23 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 		}).
 		On(gaba.ExitCodeBack, settings)
 
+	gaba.AddState(fsm, extractionSettings, func(ctx *gaba.Context) (ui.ExtractionSettingsOutput, gaba.ExitCode) {
+		config, _ := gaba.Get[*internal.Config](ctx)
+
+		screen := ui.NewExtractionSettingsScreen()
+		result, err := screen.Draw(ui.ExtractionSettingsInput{
+			Config: config,
+		})
+
+		if err != nil {
+			return ui.ExtractionSettingsOutput{Config: config}, gaba.ExitCodeError
+		}
+
+		return result.Value, result.ExitCode
+	}).
+		On(gaba.ExitCodeSuccess, settings).
+		On(gaba.ExitCodeBack, settings)
+
 	gaba.AddState(fsm, saveSyncSettings, func(ctx *gaba.Context) (ui.SaveSyncSettingsOutput, gaba.ExitCode) {
 		config, _ := gaba.Get[*internal.Config](ctx)
 		currentCFW, _ := gaba.Get[cfw.CFW](ctx)
@@ -630,6 +757,9 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 		OnWithHook(gaba.ExitCodeSuccess, settings, func(ctx *gaba.Context) error {
 			output, _ := gaba.Get[ui.SaveSyncSettingsOutput](ctx)
 			gaba.Set(ctx, output.Config)
+			if syncScheduler != nil {
+				syncScheduler.SetInterval(output.Config.SaveSyncInterval)
+			}
 			triggerAutoSync()
 			return nil
 		}).
@@ -660,6 +790,8 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 		On(gaba.ExitCodeSuccess, settings).
 		On(constants.ExitCodeRefreshCache, refreshCache).
 		On(constants.ExitCodeSyncArtwork, artworkSync).
+		On(constants.ExitCodeRebuildPlaylists, rebuildPlaylists).
+		On(constants.ExitCodeVerifyGames, verifyGames).
 		On(gaba.ExitCodeBack, settings)
 
 	gaba.AddState(fsm, settingsPlatformMapping, func(ctx *gaba.Context) (ui.PlatformMappingOutput, gaba.ExitCode) {
@@ -918,6 +1050,44 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 	}).
 		On(gaba.ExitCodeBack, platformSelection)
 
+	gaba.AddState(fsm, restoreSaves, func(ctx *gaba.Context) (ui.RestoreSavesOutput, gaba.ExitCode) {
+		config, _ := gaba.Get[*internal.Config](ctx)
+		host, _ := gaba.Get[romm.Host](ctx)
+
+		screen := ui.NewRestoreSavesScreen()
+		output := screen.Execute(config, host)
+
+		return output, gaba.ExitCodeBack
+	}).
+		On(gaba.ExitCodeBack, settings)
+
+	gaba.AddState(fsm, cleanUpSaves, func(ctx *gaba.Context) (ui.CleanUpSavesOutput, gaba.ExitCode) {
+		config, _ := gaba.Get[*internal.Config](ctx)
+		host, _ := gaba.Get[romm.Host](ctx)
+
+		screen := ui.NewCleanUpSavesScreen()
+		output := screen.Execute(config, host)
+
+		return output, gaba.ExitCodeBack
+	}).
+		On(gaba.ExitCodeBack, settings)
+
+	gaba.AddState(fsm, downloadQueue, func(ctx *gaba.Context) (ui.DownloadQueueOutput, gaba.ExitCode) {
+		screen := ui.NewDownloadQueueScreen()
+		output := screen.Execute(backgroundDownloads)
+
+		return output, gaba.ExitCodeBack
+	}).
+		On(gaba.ExitCodeBack, settings)
+
+	gaba.AddState(fsm, activity, func(ctx *gaba.Context) (ui.ActivityOutput, gaba.ExitCode) {
+		screen := ui.NewActivityScreen()
+		output := screen.Execute()
+
+		return output, gaba.ExitCodeBack
+	}).
+		On(gaba.ExitCodeBack, settings)
+
 	gaba.AddState(fsm, biosDownload, func(ctx *gaba.Context) (ui.BIOSDownloadOutput, gaba.ExitCode) {
 		config, _ := gaba.Get[*internal.Config](ctx)
 		host, _ := gaba.Get[romm.Host](ctx)
@@ -949,6 +1119,26 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 	}).
 		On(gaba.ExitCodeBack, advancedSettings)
 
+	gaba.AddState(fsm, rebuildPlaylists, func(ctx *gaba.Context) (ui.RebuildPlaylistsOutput, gaba.ExitCode) {
+		config, _ := gaba.Get[*internal.Config](ctx)
+
+		screen := ui.NewRebuildPlaylistsScreen()
+		output := screen.Execute(*config)
+
+		return output, gaba.ExitCodeBack
+	}).
+		On(gaba.ExitCodeBack, advancedSettings)
+
+	gaba.AddState(fsm, verifyGames, func(ctx *gaba.Context) (ui.VerifyGamesOutput, gaba.ExitCode) {
+		config, _ := gaba.Get[*internal.Config](ctx)
+
+		screen := ui.NewVerifyGamesScreen()
+		output := screen.Execute(*config)
+
+		return output, gaba.ExitCodeBack
+	}).
+		On(gaba.ExitCodeBack, advancedSettings)
+
 	gaba.AddState(fsm, updateCheck, func(ctx *gaba.Context) (ui.UpdateOutput, gaba.ExitCode) {
 		currentCFW, _ := gaba.Get[cfw.CFW](ctx)
 
@@ -973,6 +1163,39 @@ func buildFSM(config *internal.Config, c cfw.CFW, platforms []romm.Platform, qui
 	return fsm.Start(platformSelection)
 }
 
+// queueDownloads hands games to the background download queue. Games that won't fit on
+// the device alongside what is already queued can be dropped first. The download screen
+// is only used when there is no queue.
+func queueDownloads(config *internal.Config, host romm.Host, nav *NavState, platform romm.Platform, games []romm.Rom, allGames []romm.Rom) {
+	nav.CurrentGames = allGames
+
+	if backgroundDownloads != nil {
+		planned, ok := ui.NewStorageCheckScreen().Execute(backgroundDownloads.Plan(platform, games), func(selected []cache.QueuedDownload) []download.SpaceShortfall {
+			return download.CheckQueueSpace(*config, backgroundDownloads.Items(), selected)
+		})
+		if !ok || len(planned) == 0 {
+			return
+		}
+		if backgroundDownloads.Enqueue(planned) == 0 {
+			gaba.GetLogger().Error("Unable to queue any downloads", "count", len(planned))
+		}
+		return
+	}
+
+	downloadOutput := ui.NewDownloadScreen().Execute(*config, host, platform, games, allGames, nav.SearchFilter)
+	nav.CurrentGames = downloadOutput.AllGames
+	nav.SearchFilter = downloadOutput.SearchFilter
+	triggerAutoSync()
+}
+
+// syncSubscriptions keeps the subscribed collections on the device as part of each
+// background sync.
+func syncSubscriptions() {
+	if _, err := backgroundDownloads.SyncSubscriptions(subscriptionRefreshInterval); err != nil {
+		gaba.GetLogger().Error("Background subscription sync failed", "error", err)
+	}
+}
+
 func triggerAutoSync() {
 	if autoSync != nil {
 		autoSync.Trigger()
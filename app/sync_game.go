@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"grout/cache"
+	"grout/internal"
+	"grout/internal/constants"
+	"grout/romm"
+	"grout/sync"
+	"os"
+	"path/filepath"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+const syncGameUsage = "usage: grout sync-game <rom path> --pre|--post"
+
+// runSyncGame is the non-interactive entry point used by CFW launch scripts:
+//
+//	grout sync-game <rom path> --pre   # before the emulator starts
+//	grout sync-game <rom path> --post  # after the emulator exits
+//
+// It never opens a window and never fails the launch: being offline, an unknown
+// ROM or a sync error are logged and the process still exits cleanly.
+func runSyncGame(args []string) int {
+	var romPath string
+	var action sync.SyncAction
+
+	for _, arg := range args {
+		switch arg {
+		case "--pre":
+			action = sync.Download
+		case "--post":
+			action = sync.Upload
+		default:
+			romPath = arg
+		}
+	}
+
+	if romPath == "" || action == "" {
+		fmt.Fprintln(os.Stderr, syncGameUsage)
+		return 2
+	}
+
+	// Launch scripts call us from the emulator's working directory, but the
+	// config, cache and logs all live next to the binary. A relative ROM path
+	// is resolved before leaving that directory.
+	if abs, err := filepath.Abs(romPath); err == nil {
+		romPath = abs
+	}
+	if exe, err := os.Executable(); err == nil {
+		if err := os.Chdir(filepath.Dir(exe)); err != nil {
+			fmt.Fprintln(os.Stderr, "unable to change directory:", err)
+			return 0
+		}
+	}
+
+	gaba.SetLogFilename("sync-game.log")
+	logger := gaba.GetLogger()
+
+	config, err := internal.LoadConfig()
+	if err != nil || len(config.Hosts) == 0 {
+		logger.Info("SyncGame: Grout is not configured, skipping", "error", err)
+		return 0
+	}
+
+	if config.LogLevel != "" {
+		gaba.SetRawLogLevel(config.LogLevel)
+	}
+
+	if config.SaveSyncMode == "off" {
+		logger.Debug("SyncGame: Save sync is off, skipping")
+		return 0
+	}
+
+	host := config.Hosts[0]
+
+	if err := romm.NewClientFromHost(host, constants.ValidationTimeout).ValidateConnection(); err != nil {
+		logger.Info("SyncGame: RomM is unreachable, skipping", "error", err)
+		return 0
+	}
+
+	if err := cache.InitCacheManager(host, config); err != nil {
+		logger.Error("SyncGame: Failed to initialize cache manager", "error", err)
+		return 0
+	}
+	defer cache.GetCacheManager().Close()
+
+	config.ApiTimeout = constants.GameSyncTimeout
+
+	logger.Debug("SyncGame: Starting", "rom", romPath, "action", action)
+
+	results, err := sync.SyncGame(host, config, romPath, action)
+	if err != nil {
+		logger.Info("SyncGame: Unable to sync save", "rom", romPath, "error", err)
+		return 0
+	}
+
+	for _, result := range results {
+		logger.Info("SyncGame: Finished",
+			"game", result.GameName,
+			"action", result.Action,
+			"success", result.Success,
+			"error", result.Error)
+	}
+
+	return 0
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"grout/cfw"
+	"grout/internal/fileutil"
 	"grout/internal/jsonutil"
 	"io"
 	"os"
@@ -52,7 +53,7 @@ func SaveFile(biosFile File, platformFSSlug string, data []byte) error {
 			return fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
 
-		if err := os.WriteFile(filePath, data, 0644); err != nil {
+		if err := fileutil.WriteFileAtomic(filePath, data, 0644); err != nil {
 			return fmt.Errorf("failed to write file %s: %w", filePath, err)
 		}
 	}
@@ -0,0 +1,272 @@
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"grout/romm"
+	"time"
+)
+
+type DownloadState string
+
+const (
+	DownloadQueued DownloadState = "queued"
+	DownloadActive DownloadState = "downloading"
+	DownloadPaused DownloadState = "paused"
+	DownloadFailed DownloadState = "failed"
+)
+
+// DownloadFailure says why a failed download stopped.
+type DownloadFailure string
+
+const (
+	FailureError   DownloadFailure = "error"
+	FailureDamaged DownloadFailure = "damaged"
+)
+
+// QueuedDownload is a ROM waiting in the background download queue. Like PendingSync it
+// survives Clear(), so a cache refresh never drops a download the user asked for.
+type QueuedDownload struct {
+	ID          int64
+	Rom         romm.Rom
+	Platform    romm.Platform
+	URL         string
+	Location    string
+	ArtURL      string
+	ArtLocation string
+	State       DownloadState
+	Position    int
+	BytesDone   int64
+	BytesTotal  int64
+	LastError   string
+	Failure     DownloadFailure
+	QueuedAt    time.Time
+	// Validator is the ETag or Last-Modified the partial file was fetched against, so a
+	// resumed download is never stitched onto a different version of the file
+	Validator string
+	// Started is when the download began, for its history entry. It isn't stored
+	Started time.Time
+}
+
+func (cm *Manager) GetQueuedDownloads() ([]QueuedDownload, error) {
+	if cm == nil || !cm.initialized {
+		return nil, ErrNotInitialized
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	rows, err := cm.db.Query(`
+		SELECT id, rom_json, platform_json, url, location, art_url, art_location,
+		       state, position, bytes_done, bytes_total, validator, last_error, failure, queued_at
+		FROM download_queue ORDER BY position, id
+	`)
+	if err != nil {
+		cm.stats.recordError()
+		return nil, newCacheError("get", "download_queue", "", err)
+	}
+	defer rows.Close()
+
+	var queued []QueuedDownload
+	for rows.Next() {
+		var d QueuedDownload
+		var romJSON, platformJSON, state, failure string
+		var queuedAt sql.NullTime
+
+		if err := rows.Scan(&d.ID, &romJSON, &platformJSON, &d.URL, &d.Location, &d.ArtURL, &d.ArtLocation,
+			&state, &d.Position, &d.BytesDone, &d.BytesTotal, &d.Validator, &d.LastError, &failure, &queuedAt); err != nil {
+			continue
+		}
+
+		if err := json.Unmarshal([]byte(romJSON), &d.Rom); err != nil {
+			continue
+		}
+		if err := json.Unmarshal([]byte(platformJSON), &d.Platform); err != nil {
+			continue
+		}
+
+		d.State = DownloadState(state)
+		d.Failure = DownloadFailure(failure)
+		d.QueuedAt = queuedAt.Time
+		queued = append(queued, d)
+	}
+
+	cm.stats.recordHit()
+	return queued, rows.Err()
+}
+
+// AddQueuedDownload appends a download to the end of the queue and returns its ID.
+// A ROM already in the queue is not added twice.
+func (cm *Manager) AddQueuedDownload(d QueuedDownload) (int64, error) {
+	if cm == nil || !cm.initialized {
+		return 0, ErrNotInitialized
+	}
+
+	key := fmt.Sprintf("%d", d.Rom.ID)
+
+	romJSON, err := json.Marshal(d.Rom)
+	if err != nil {
+		return 0, newCacheError("save", "download_queue", key, err)
+	}
+	platformJSON, err := json.Marshal(d.Platform)
+	if err != nil {
+		return 0, newCacheError("save", "download_queue", key, err)
+	}
+
+	if d.State == "" {
+		d.State = DownloadQueued
+	}
+	if d.QueuedAt.IsZero() {
+		d.QueuedAt = time.Now()
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	var existing int64
+	err = cm.db.QueryRow(`SELECT id FROM download_queue WHERE rom_id = ?`, d.Rom.ID).Scan(&existing)
+	if err == nil {
+		return existing, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, newCacheError("save", "download_queue", key, err)
+	}
+
+	result, err := cm.db.Exec(`
+		INSERT INTO download_queue
+		(rom_id, rom_name, rom_json, platform_json, url, location, art_url, art_location,
+		 state, position, bytes_done, bytes_total, last_error, queued_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM download_queue), ?, ?, ?, ?)
+	`, d.Rom.ID, d.Rom.Name, string(romJSON), string(platformJSON), d.URL, d.Location, d.ArtURL, d.ArtLocation,
+		string(d.State), d.BytesDone, d.BytesTotal, d.LastError, d.QueuedAt)
+	if err != nil {
+		return 0, newCacheError("save", "download_queue", key, err)
+	}
+
+	return result.LastInsertId()
+}
+
+func (cm *Manager) SetQueuedDownloadState(id int64, state DownloadState, lastError string) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`UPDATE download_queue SET state = ?, last_error = ?, failure = '' WHERE id = ?`, string(state), lastError, id)
+	if err != nil {
+		return newCacheError("update", "download_queue", fmt.Sprintf("%d", id), err)
+	}
+
+	return nil
+}
+
+// SetQueuedDownloadFailed marks a download as failed, recording why.
+func (cm *Manager) SetQueuedDownloadFailed(id int64, failure DownloadFailure, lastError string) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`UPDATE download_queue SET state = ?, failure = ?, last_error = ? WHERE id = ?`,
+		string(DownloadFailed), string(failure), lastError, id)
+	if err != nil {
+		return newCacheError("update", "download_queue", fmt.Sprintf("%d", id), err)
+	}
+
+	return nil
+}
+
+func (cm *Manager) SetQueuedDownloadProgress(id int64, bytesDone, bytesTotal int64) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`UPDATE download_queue SET bytes_done = ?, bytes_total = ? WHERE id = ?`, bytesDone, bytesTotal, id)
+	if err != nil {
+		return newCacheError("update", "download_queue", fmt.Sprintf("%d", id), err)
+	}
+
+	return nil
+}
+
+func (cm *Manager) SetQueuedDownloadValidator(id int64, validator string) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`UPDATE download_queue SET validator = ? WHERE id = ?`, validator, id)
+	if err != nil {
+		return newCacheError("update", "download_queue", fmt.Sprintf("%d", id), err)
+	}
+
+	return nil
+}
+
+// ResetActiveDownloads returns downloads left active by a previous run to the queue, so
+// they resume from the bytes already written.
+func (cm *Manager) ResetActiveDownloads() error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`UPDATE download_queue SET state = ? WHERE state = ?`, string(DownloadQueued), string(DownloadActive))
+	if err != nil {
+		return newCacheError("update", "download_queue", "", err)
+	}
+
+	return nil
+}
+
+// ReorderQueuedDownloads stores the queue order given as a list of download IDs.
+func (cm *Manager) ReorderQueuedDownloads(ids []int64) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	tx, err := cm.db.Begin()
+	if err != nil {
+		return newCacheError("reorder", "download_queue", "", err)
+	}
+	defer tx.Rollback()
+
+	for i, id := range ids {
+		if _, err := tx.Exec(`UPDATE download_queue SET position = ? WHERE id = ?`, i+1, id); err != nil {
+			return newCacheError("reorder", "download_queue", fmt.Sprintf("%d", id), err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (cm *Manager) DeleteQueuedDownload(id int64) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`DELETE FROM download_queue WHERE id = ?`, id)
+	if err != nil {
+		return newCacheError("delete", "download_queue", fmt.Sprintf("%d", id), err)
+	}
+
+	return nil
+}
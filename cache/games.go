@@ -512,7 +512,9 @@ func (cm *Manager) GetRomIDByFilename(fsSlug, filename string) (int, string, boo
 	return romID, romName, true
 }
 
-func (cm *Manager) GetRomByHash(md5, sha1, crc string) (int, string, bool) {
+// GetRomByHash finds a game on the platform with fsSlug by its MD5, SHA1 or CRC32, in
+// that order. Games with the same hash on other platforms are never returned.
+func (cm *Manager) GetRomByHash(fsSlug, md5, sha1, crc string) (int, string, bool) {
 	if cm == nil || !cm.initialized {
 		return 0, "", false
 	}
@@ -523,24 +525,16 @@ func (cm *Manager) GetRomByHash(md5, sha1, crc string) (int, string, bool) {
 	var romID int
 	var romName string
 
-	if md5 != "" {
-		err := cm.db.QueryRow(`SELECT id, name FROM games WHERE md5_hash = ?`, md5).Scan(&romID, &romName)
-		if err == nil {
-			cm.stats.recordHit()
-			return romID, romName, true
-		}
-	}
-
-	if sha1 != "" {
-		err := cm.db.QueryRow(`SELECT id, name FROM games WHERE sha1_hash = ?`, sha1).Scan(&romID, &romName)
-		if err == nil {
-			cm.stats.recordHit()
-			return romID, romName, true
+	for _, lookup := range []struct{ column, hash string }{
+		{"md5_hash", md5},
+		{"sha1_hash", sha1},
+		{"crc_hash", crc},
+	} {
+		if lookup.hash == "" {
+			continue
 		}
-	}
-
-	if crc != "" {
-		err := cm.db.QueryRow(`SELECT id, name FROM games WHERE crc_hash = ?`, crc).Scan(&romID, &romName)
+		err := cm.db.QueryRow(`SELECT id, name FROM games WHERE platform_fs_slug = ? AND `+lookup.column+` = ?`,
+			fsSlug, lookup.hash).Scan(&romID, &romName)
 		if err == nil {
 			cm.stats.recordHit()
 			return romID, romName, true
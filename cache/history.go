@@ -0,0 +1,214 @@
+package cache
+
+import (
+	"database/sql"
+	"time"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+const historySchema = `
+	CREATE TABLE IF NOT EXISTS history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		action TEXT NOT NULL,
+		rom_id INTEGER NOT NULL,
+		rom_name TEXT NOT NULL,
+		platform_slug TEXT DEFAULT '',
+		platform_name TEXT DEFAULT '',
+		size_bytes INTEGER DEFAULT 0,
+		duration_ms INTEGER DEFAULT 0,
+		host TEXT DEFAULT '',
+		verification TEXT DEFAULT '',
+		error TEXT DEFAULT '',
+		at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)
+`
+
+// historyLimit is how many entries the history keeps; the oldest are dropped beyond it.
+const historyLimit = 5000
+
+type HistoryAction string
+
+const (
+	HistoryDownload  HistoryAction = "download"
+	HistoryUpdate    HistoryAction = "update"
+	HistoryExtract   HistoryAction = "extract"
+	HistoryUninstall HistoryAction = "uninstall"
+)
+
+// Verification outcomes recorded for downloads.
+const (
+	VerificationPassed  = "verified"
+	VerificationSkipped = "no checksum"
+	VerificationFailed  = "damaged"
+)
+
+// HistoryEntry is one thing done to a game on the device. The history describes the
+// device rather than a server, so it is kept in its own database that survives Clear()
+// and logging out.
+type HistoryEntry struct {
+	ID           int64
+	Action       HistoryAction
+	RomID        int
+	RomName      string
+	PlatformSlug string
+	PlatformName string
+	SizeBytes    int64
+	Duration     time.Duration
+	Host         string
+	Verification string
+	Error        string
+	At           time.Time
+}
+
+func (cm *Manager) AddHistory(entry HistoryEntry) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.historyDB.Exec(`
+		INSERT INTO history (action, rom_id, rom_name, platform_slug, platform_name, size_bytes, duration_ms, host, verification, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.Action, entry.RomID, entry.RomName, entry.PlatformSlug, entry.PlatformName, entry.SizeBytes,
+		entry.Duration.Milliseconds(), entry.Host, entry.Verification, entry.Error)
+	if err != nil {
+		cm.stats.recordError()
+		return newCacheError("save", "history", entry.RomName, err)
+	}
+
+	_, err = cm.historyDB.Exec(`DELETE FROM history WHERE id <= (SELECT MAX(id) FROM history) - ?`, historyLimit)
+	if err != nil {
+		return newCacheError("delete", "history", "", err)
+	}
+
+	return nil
+}
+
+// GetHistory returns the most recent entries first. A limit of 0 returns all of them.
+func (cm *Manager) GetHistory(limit int) ([]HistoryEntry, error) {
+	if cm == nil || !cm.initialized {
+		return nil, ErrNotInitialized
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := cm.historyDB.Query(`
+		SELECT id, action, rom_id, rom_name, platform_slug, platform_name, size_bytes, duration_ms, host, verification, error, at
+		FROM history ORDER BY id DESC LIMIT ?
+	`, limit)
+	if err != nil {
+		cm.stats.recordError()
+		return nil, newCacheError("get", "history", "", err)
+	}
+	defer rows.Close()
+
+	var entries []HistoryEntry
+	for rows.Next() {
+		var e HistoryEntry
+		var durationMs int64
+		var at sql.NullTime
+		if err := rows.Scan(&e.ID, &e.Action, &e.RomID, &e.RomName, &e.PlatformSlug, &e.PlatformName, &e.SizeBytes,
+			&durationMs, &e.Host, &e.Verification, &e.Error, &at); err != nil {
+			cm.stats.recordError()
+			return nil, newCacheError("get", "history", "", err)
+		}
+		e.Duration = time.Duration(durationMs) * time.Millisecond
+		e.At = at.Time
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		cm.stats.recordError()
+		return nil, newCacheError("get", "history", "", err)
+	}
+
+	cm.stats.recordHit()
+	return entries, nil
+}
+
+// openHistoryDB opens the history database, moving over any history an earlier version
+// kept in the cache database.
+func openHistoryDB(cacheDB *sql.DB) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", getHistoryDBPath()+"?_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	if _, err := db.Exec(historySchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := migrateHistory(cacheDB, db); err != nil {
+		gaba.GetLogger().Warn("Unable to move history out of the cache", "error", err)
+	}
+
+	return db, nil
+}
+
+func migrateHistory(cacheDB, historyDB *sql.DB) error {
+	var name string
+	err := cacheDB.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'history'`).Scan(&name)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := cacheDB.Query(`
+		SELECT action, rom_id, rom_name, platform_slug, platform_name, size_bytes, duration_ms, host, verification, error, at
+		FROM history ORDER BY id
+	`)
+	if err != nil {
+		return err
+	}
+
+	tx, err := historyDB.Begin()
+	if err != nil {
+		rows.Close()
+		return err
+	}
+	defer tx.Rollback()
+
+	for rows.Next() {
+		var action, romName, platformSlug, platformName, host, verification, errText string
+		var romID int
+		var sizeBytes, durationMs int64
+		var at sql.NullTime
+		if err := rows.Scan(&action, &romID, &romName, &platformSlug, &platformName, &sizeBytes, &durationMs,
+			&host, &verification, &errText, &at); err != nil {
+			rows.Close()
+			return err
+		}
+		_, err := tx.Exec(`
+			INSERT INTO history (action, rom_id, rom_name, platform_slug, platform_name, size_bytes, duration_ms, host, verification, error, at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, action, romID, romName, platformSlug, platformName, sizeBytes, durationMs, host, verification, errText, at)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	_, err = cacheDB.Exec(`DROP TABLE history`)
+	return err
+}
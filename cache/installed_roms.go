@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// InstalledRom records the version of a ROM that was last installed on the device, so a
+// later change on RomM can be spotted. Like verified files, it describes the device and
+// survives Clear().
+type InstalledRom struct {
+	RomID           int
+	PlatformFSSlug  string
+	FileName        string
+	BaseName        string
+	Revision        string
+	Hash            string
+	ServerUpdatedAt time.Time
+	// ExtractedFiles are the files a single archive was extracted to, relative to the
+	// platform's ROM directory
+	ExtractedFiles []string
+	InstalledAt    time.Time
+}
+
+func (cm *Manager) GetInstalledRoms() (map[int]InstalledRom, error) {
+	if cm == nil || !cm.initialized {
+		return nil, ErrNotInitialized
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	rows, err := cm.db.Query(`
+		SELECT rom_id, platform_fs_slug, file_name, base_name, revision, hash, server_updated_at, extracted_files, installed_at
+		FROM installed_roms
+	`)
+	if err != nil {
+		cm.stats.recordError()
+		return nil, newCacheError("get", "installed_roms", "", err)
+	}
+	defer rows.Close()
+
+	installed := make(map[int]InstalledRom)
+	for rows.Next() {
+		var r InstalledRom
+		var serverUpdatedAt, installedAt sql.NullTime
+		var extractedJSON sql.NullString
+		if err := rows.Scan(&r.RomID, &r.PlatformFSSlug, &r.FileName, &r.BaseName, &r.Revision, &r.Hash, &serverUpdatedAt, &extractedJSON, &installedAt); err != nil {
+			continue
+		}
+		r.ServerUpdatedAt = serverUpdatedAt.Time
+		r.ExtractedFiles = decodeExtractedFiles(extractedJSON.String)
+		r.InstalledAt = installedAt.Time
+		installed[r.RomID] = r
+	}
+
+	cm.stats.recordHit()
+	return installed, rows.Err()
+}
+
+func (cm *Manager) GetInstalledRom(romID int) (InstalledRom, bool) {
+	if cm == nil || !cm.initialized {
+		return InstalledRom{}, false
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	r := InstalledRom{RomID: romID}
+	var serverUpdatedAt, installedAt sql.NullTime
+	var extractedJSON sql.NullString
+	err := cm.db.QueryRow(`
+		SELECT platform_fs_slug, file_name, base_name, revision, hash, server_updated_at, extracted_files, installed_at
+		FROM installed_roms WHERE rom_id = ?
+	`, romID).Scan(&r.PlatformFSSlug, &r.FileName, &r.BaseName, &r.Revision, &r.Hash, &serverUpdatedAt, &extractedJSON, &installedAt)
+
+	if err == sql.ErrNoRows {
+		cm.stats.recordMiss()
+		return InstalledRom{}, false
+	}
+	if err != nil {
+		cm.stats.recordError()
+		return InstalledRom{}, false
+	}
+
+	r.ServerUpdatedAt = serverUpdatedAt.Time
+	r.ExtractedFiles = decodeExtractedFiles(extractedJSON.String)
+	r.InstalledAt = installedAt.Time
+	cm.stats.recordHit()
+	return r, true
+}
+
+func (cm *Manager) SaveInstalledRom(r InstalledRom) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	if r.InstalledAt.IsZero() {
+		r.InstalledAt = time.Now()
+	}
+
+	extractedJSON := ""
+	if len(r.ExtractedFiles) > 0 {
+		data, err := json.Marshal(r.ExtractedFiles)
+		if err != nil {
+			return newCacheError("save", "installed_roms", fmt.Sprintf("%d", r.RomID), err)
+		}
+		extractedJSON = string(data)
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`
+		INSERT OR REPLACE INTO installed_roms (rom_id, platform_fs_slug, file_name, base_name, revision, hash, server_updated_at, extracted_files, installed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, r.RomID, r.PlatformFSSlug, r.FileName, r.BaseName, r.Revision, r.Hash, r.ServerUpdatedAt, extractedJSON, r.InstalledAt)
+	if err != nil {
+		return newCacheError("save", "installed_roms", fmt.Sprintf("%d", r.RomID), err)
+	}
+
+	return nil
+}
+
+func (cm *Manager) DeleteInstalledRom(romID int) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`DELETE FROM installed_roms WHERE rom_id = ?`, romID)
+	if err != nil {
+		return newCacheError("delete", "installed_roms", fmt.Sprintf("%d", romID), err)
+	}
+
+	return nil
+}
+
+func decodeExtractedFiles(data string) []string {
+	if data == "" {
+		return nil
+	}
+	var files []string
+	if err := json.Unmarshal([]byte(data), &files); err != nil {
+		return nil
+	}
+	return files
+}
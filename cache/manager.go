@@ -17,6 +17,7 @@ import (
 type Manager struct {
 	db          *sql.DB
 	dbPath      string
+	historyDB   *sql.DB
 	mu          sync.RWMutex
 	host        romm.Host
 	config      Config
@@ -95,9 +96,16 @@ func newCacheManager(host romm.Host, config Config) (*Manager, error) {
 		return nil, newCacheError("init", "", "", err)
 	}
 
+	historyDB, err := openHistoryDB(db)
+	if err != nil {
+		db.Close()
+		return nil, newCacheError("init", "history", "", err)
+	}
+
 	cm := &Manager{
 		db:          db,
 		dbPath:      dbPath,
+		historyDB:   historyDB,
 		host:        host,
 		config:      config,
 		initialized: true,
@@ -117,6 +125,9 @@ func (cm *Manager) Close() error {
 	defer cm.mu.Unlock()
 
 	cm.initialized = false
+	if cm.historyDB != nil {
+		cm.historyDB.Close()
+	}
 	return cm.db.Close()
 }
 
@@ -333,6 +344,15 @@ func getCacheDBPath() string {
 	return filepath.Join(wd, ".cache", "grout.db")
 }
 
+// getHistoryDBPath keeps the history outside the cache folder, which logging out deletes.
+func getHistoryDBPath() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "history.db")
+	}
+	return filepath.Join(wd, "history.db")
+}
+
 func GetArtworkCacheDir() string {
 	wd, err := os.Getwd()
 	if err != nil {
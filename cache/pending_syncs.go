@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"grout/romm"
+	"time"
+)
+
+// PendingSync is a save upload or download that failed and is waiting to be retried.
+// Like SaveSyncState it survives Clear(), so a cache refresh never drops a queued upload.
+type PendingSync struct {
+	Key           string
+	Action        string
+	RomID         int
+	RomName       string
+	FSSlug        string
+	GameBase      string
+	SavePath      string
+	Remote        romm.Save
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	QueuedAt      time.Time
+
+	// Folder saves have no file at SavePath, so where their directories live is kept too
+	FolderEmulatorDir string
+	FolderRoot        string
+	FolderGameID      string
+}
+
+func (cm *Manager) GetPendingSyncs() ([]PendingSync, error) {
+	if cm == nil || !cm.initialized {
+		return nil, ErrNotInitialized
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	rows, err := cm.db.Query(`
+		SELECT ` + pendingSyncColumns + `
+		FROM pending_syncs ORDER BY queued_at
+	`)
+	if err != nil {
+		cm.stats.recordError()
+		return nil, newCacheError("get", "pending_syncs", "", err)
+	}
+	defer rows.Close()
+
+	var pending []PendingSync
+	for rows.Next() {
+		if p, err := scanPendingSync(rows); err == nil {
+			pending = append(pending, p)
+		}
+	}
+
+	cm.stats.recordHit()
+	return pending, rows.Err()
+}
+
+func (cm *Manager) GetPendingSync(key string) (PendingSync, bool) {
+	if cm == nil || !cm.initialized {
+		return PendingSync{}, false
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	row := cm.db.QueryRow(`
+		SELECT `+pendingSyncColumns+`
+		FROM pending_syncs WHERE sync_key = ?
+	`, key)
+
+	p, err := scanPendingSync(row)
+	if err == sql.ErrNoRows {
+		cm.stats.recordMiss()
+		return PendingSync{}, false
+	}
+	if err != nil {
+		cm.stats.recordError()
+		return PendingSync{}, false
+	}
+
+	cm.stats.recordHit()
+	return p, true
+}
+
+// pendingSyncColumns are read in the order scanPendingSync expects.
+const pendingSyncColumns = `sync_key, action, rom_id, rom_name, fs_slug, game_base, save_path,
+		       folder_emulator_dir, folder_root, folder_game_id, remote_json,
+		       attempts, last_error, next_attempt_at, queued_at`
+
+func scanPendingSync(row interface{ Scan(...any) error }) (PendingSync, error) {
+	var p PendingSync
+	var remoteJSON string
+	var nextAttemptAt, queuedAt sql.NullTime
+
+	if err := row.Scan(&p.Key, &p.Action, &p.RomID, &p.RomName, &p.FSSlug, &p.GameBase, &p.SavePath,
+		&p.FolderEmulatorDir, &p.FolderRoot, &p.FolderGameID, &remoteJSON, &p.Attempts, &p.LastError, &nextAttemptAt, &queuedAt); err != nil {
+		return PendingSync{}, err
+	}
+
+	if remoteJSON != "" {
+		if err := json.Unmarshal([]byte(remoteJSON), &p.Remote); err != nil {
+			return PendingSync{}, err
+		}
+	}
+
+	p.NextAttemptAt = nextAttemptAt.Time
+	p.QueuedAt = queuedAt.Time
+	return p, nil
+}
+
+func (cm *Manager) SavePendingSync(p PendingSync) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	remoteJSON := ""
+	if p.Remote.ID != 0 {
+		data, err := json.Marshal(p.Remote)
+		if err != nil {
+			return newCacheError("save", "pending_syncs", p.Key, err)
+		}
+		remoteJSON = string(data)
+	}
+
+	if p.QueuedAt.IsZero() {
+		p.QueuedAt = time.Now()
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`
+		INSERT OR REPLACE INTO pending_syncs
+		(sync_key, action, rom_id, rom_name, fs_slug, game_base, save_path,
+		 folder_emulator_dir, folder_root, folder_game_id, remote_json,
+		 attempts, last_error, next_attempt_at, queued_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, p.Key, p.Action, p.RomID, p.RomName, p.FSSlug, p.GameBase, p.SavePath,
+		p.FolderEmulatorDir, p.FolderRoot, p.FolderGameID, remoteJSON,
+		p.Attempts, p.LastError, p.NextAttemptAt, p.QueuedAt)
+	if err != nil {
+		return newCacheError("save", "pending_syncs", p.Key, err)
+	}
+
+	return nil
+}
+
+func (cm *Manager) DeletePendingSync(key string) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`DELETE FROM pending_syncs WHERE sync_key = ?`, key)
+	if err != nil {
+		return newCacheError("delete", "pending_syncs", key, err)
+	}
+
+	return nil
+}
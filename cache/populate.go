@@ -262,6 +262,16 @@ func (cm *Manager) fetchBIOSAvailability(platforms []romm.Platform) {
 	wg.Wait()
 }
 
+// RefreshCollections fetches the collections and their games from RomM again.
+func (cm *Manager) RefreshCollections() error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.fetchAndCacheCollectionsWithProgress(nil)
+	return cm.RecordRefreshTime(MetaKeyCollectionsRefreshedAt)
+}
+
 func (cm *Manager) RefreshPlatformGames(platform romm.Platform) error {
 	if cm == nil || !cm.initialized {
 		return ErrNotInitialized
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"database/sql"
+	"time"
+)
+
+// LocalRomHash records the CRC32 of a ROM file on the device and the RomM ROM it matched, if any.
+// Entries are only trusted while the file's size and modification time are unchanged.
+type LocalRomHash struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	CRC     string
+	RomID   int
+	RomName string
+}
+
+func (cm *Manager) GetLocalRomHash(path string, size int64, modTime time.Time) (LocalRomHash, bool) {
+	if cm == nil || !cm.initialized {
+		return LocalRomHash{}, false
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	entry := LocalRomHash{Path: path, Size: size, ModTime: modTime}
+	err := cm.db.QueryRow(`
+		SELECT crc_hash, rom_id, rom_name FROM local_rom_hashes
+		WHERE path = ? AND size = ? AND mod_time = ?
+	`, path, size, modTime.UnixNano()).Scan(&entry.CRC, &entry.RomID, &entry.RomName)
+
+	if err == sql.ErrNoRows {
+		cm.stats.recordMiss()
+		return LocalRomHash{}, false
+	}
+	if err != nil {
+		cm.stats.recordError()
+		return LocalRomHash{}, false
+	}
+
+	cm.stats.recordHit()
+	return entry, true
+}
+
+func (cm *Manager) SaveLocalRomHash(entry LocalRomHash) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`
+		INSERT OR REPLACE INTO local_rom_hashes (path, size, mod_time, crc_hash, rom_id, rom_name, hashed_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, entry.Path, entry.Size, entry.ModTime.UnixNano(), entry.CRC, entry.RomID, entry.RomName)
+	if err != nil {
+		return newCacheError("save", "local_rom_hashes", entry.Path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SaveSyncState is the fingerprint of a local save as of its last successful sync.
+// It is intentionally not removed by Clear(), since it describes the device rather than the server.
+type SaveSyncState struct {
+	SavePath        string
+	RomID           int
+	ContentHash     string
+	RemoteSaveID    int
+	RemoteUpdatedAt time.Time
+	SyncedAt        time.Time
+}
+
+func (cm *Manager) GetSaveSyncState(savePath string) (SaveSyncState, bool) {
+	if cm == nil || !cm.initialized {
+		return SaveSyncState{}, false
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	var state SaveSyncState
+	var remoteUpdatedAt, syncedAt sql.NullTime
+	err := cm.db.QueryRow(`
+		SELECT save_path, rom_id, content_hash, remote_save_id, remote_updated_at, synced_at
+		FROM save_sync_state WHERE save_path = ?
+	`, savePath).Scan(&state.SavePath, &state.RomID, &state.ContentHash, &state.RemoteSaveID, &remoteUpdatedAt, &syncedAt)
+
+	if err == sql.ErrNoRows {
+		cm.stats.recordMiss()
+		return SaveSyncState{}, false
+	}
+	if err != nil {
+		cm.stats.recordError()
+		return SaveSyncState{}, false
+	}
+
+	state.RemoteUpdatedAt = remoteUpdatedAt.Time
+	state.SyncedAt = syncedAt.Time
+
+	cm.stats.recordHit()
+	return state, true
+}
+
+func (cm *Manager) SetSaveSyncState(state SaveSyncState) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`
+		INSERT OR REPLACE INTO save_sync_state (save_path, rom_id, content_hash, remote_save_id, remote_updated_at, synced_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, state.SavePath, state.RomID, state.ContentHash, state.RemoteSaveID, state.RemoteUpdatedAt, time.Now())
+	if err != nil {
+		return newCacheError("save", "save_sync_state", state.SavePath, err)
+	}
+
+	return nil
+}
+
+func (cm *Manager) DeleteSaveSyncState(savePath string) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`DELETE FROM save_sync_state WHERE save_path = ?`, savePath)
+	if err != nil {
+		return newCacheError("delete", "save_sync_state", savePath, err)
+	}
+
+	return nil
+}
@@ -144,6 +144,126 @@ func createTables(db *sql.DB) error {
 		return err
 	}
 
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS save_sync_state (
+			save_path TEXT PRIMARY KEY,
+			rom_id INTEGER NOT NULL,
+			content_hash TEXT NOT NULL,
+			remote_save_id INTEGER DEFAULT 0,
+			remote_updated_at DATETIME,
+			synced_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS local_rom_hashes (
+			path TEXT PRIMARY KEY,
+			size INTEGER NOT NULL,
+			mod_time INTEGER NOT NULL,
+			crc_hash TEXT NOT NULL,
+			rom_id INTEGER DEFAULT 0,
+			rom_name TEXT DEFAULT '',
+			hashed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS pending_syncs (
+			sync_key TEXT PRIMARY KEY,
+			action TEXT NOT NULL,
+			rom_id INTEGER NOT NULL,
+			rom_name TEXT DEFAULT '',
+			fs_slug TEXT NOT NULL,
+			game_base TEXT NOT NULL,
+			save_path TEXT DEFAULT '',
+			folder_emulator_dir TEXT DEFAULT '',
+			folder_root TEXT DEFAULT '',
+			folder_game_id TEXT DEFAULT '',
+			remote_json TEXT DEFAULT '',
+			attempts INTEGER DEFAULT 0,
+			last_error TEXT DEFAULT '',
+			next_attempt_at DATETIME,
+			queued_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS download_queue (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			rom_id INTEGER NOT NULL,
+			rom_name TEXT NOT NULL,
+			rom_json TEXT NOT NULL,
+			platform_json TEXT NOT NULL,
+			url TEXT NOT NULL,
+			location TEXT NOT NULL,
+			art_url TEXT DEFAULT '',
+			art_location TEXT DEFAULT '',
+			state TEXT NOT NULL,
+			position INTEGER NOT NULL,
+			bytes_done INTEGER DEFAULT 0,
+			bytes_total INTEGER DEFAULT 0,
+			validator TEXT DEFAULT '',
+			last_error TEXT DEFAULT '',
+			failure TEXT DEFAULT '',
+			queued_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS verified_files (
+			rom_id INTEGER NOT NULL,
+			file_name TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			algorithm TEXT NOT NULL,
+			hash TEXT NOT NULL,
+			verified_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (rom_id, file_name)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS installed_roms (
+			rom_id INTEGER PRIMARY KEY,
+			platform_fs_slug TEXT NOT NULL,
+			file_name TEXT NOT NULL,
+			base_name TEXT NOT NULL,
+			revision TEXT DEFAULT '',
+			hash TEXT DEFAULT '',
+			server_updated_at DATETIME,
+			extracted_files TEXT DEFAULT '',
+			installed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS subscribed_roms (
+			rom_id INTEGER PRIMARY KEY,
+			data_json TEXT NOT NULL,
+			added_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
 	_, err = tx.Exec(`
 		INSERT OR REPLACE INTO cache_metadata (key, value, updated_at)
 		VALUES ('schema_version', ?, CURRENT_TIMESTAMP)
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"grout/romm"
+)
+
+// GetSubscribedRoms returns the games Grout downloaded because they were in a subscribed
+// collection, so they can be removed again once they no longer are. Games the user
+// downloaded themselves are never recorded. Like installed ROMs, these describe the
+// device and survive Clear().
+func (cm *Manager) GetSubscribedRoms() (map[int]romm.Rom, error) {
+	if cm == nil || !cm.initialized {
+		return nil, ErrNotInitialized
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	rows, err := cm.db.Query(`SELECT rom_id, data_json FROM subscribed_roms`)
+	if err != nil {
+		cm.stats.recordError()
+		return nil, newCacheError("get", "subscribed_roms", "", err)
+	}
+	defer rows.Close()
+
+	games := make(map[int]romm.Rom)
+	for rows.Next() {
+		var romID int
+		var dataJSON string
+		if err := rows.Scan(&romID, &dataJSON); err != nil {
+			cm.stats.recordError()
+			return nil, newCacheError("get", "subscribed_roms", "", err)
+		}
+
+		var game romm.Rom
+		if err := json.Unmarshal([]byte(dataJSON), &game); err != nil {
+			cm.stats.recordError()
+			return nil, newCacheError("get", "subscribed_roms", fmt.Sprintf("%d", romID), err)
+		}
+		games[romID] = game
+	}
+
+	if err := rows.Err(); err != nil {
+		cm.stats.recordError()
+		return nil, newCacheError("get", "subscribed_roms", "", err)
+	}
+
+	cm.stats.recordHit()
+	return games, nil
+}
+
+func (cm *Manager) SaveSubscribedRoms(games []romm.Rom) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	if len(games) == 0 {
+		return nil
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	tx, err := cm.db.Begin()
+	if err != nil {
+		return newCacheError("save", "subscribed_roms", "", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO subscribed_roms (rom_id, data_json) VALUES (?, ?)`)
+	if err != nil {
+		return newCacheError("save", "subscribed_roms", "", err)
+	}
+	defer stmt.Close()
+
+	for _, game := range games {
+		dataJSON, err := json.Marshal(game)
+		if err != nil {
+			return newCacheError("save", "subscribed_roms", fmt.Sprintf("%d", game.ID), err)
+		}
+		if _, err := stmt.Exec(game.ID, string(dataJSON)); err != nil {
+			return newCacheError("save", "subscribed_roms", fmt.Sprintf("%d", game.ID), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return newCacheError("save", "subscribed_roms", "", err)
+	}
+
+	return nil
+}
+
+func (cm *Manager) DeleteSubscribedRom(romID int) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`DELETE FROM subscribed_roms WHERE rom_id = ?`, romID)
+	if err != nil {
+		return newCacheError("delete", "subscribed_roms", fmt.Sprintf("%d", romID), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// VerifiedFile records a downloaded ROM file whose checksum matched RomM. It is keyed by
+// file name rather than path, since muOS moves multi-disc games after extraction, and
+// survives Clear() so an audit doesn't hash the file again while it is unchanged.
+type VerifiedFile struct {
+	RomID      int
+	FileName   string
+	Size       int64
+	Algorithm  string
+	Hash       string
+	VerifiedAt time.Time
+}
+
+func (cm *Manager) GetVerifiedFile(romID int, fileName string) (VerifiedFile, bool) {
+	if cm == nil || !cm.initialized {
+		return VerifiedFile{}, false
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	v := VerifiedFile{RomID: romID, FileName: fileName}
+	var verifiedAt sql.NullTime
+	err := cm.db.QueryRow(`
+		SELECT size, algorithm, hash, verified_at FROM verified_files
+		WHERE rom_id = ? AND file_name = ?
+	`, romID, fileName).Scan(&v.Size, &v.Algorithm, &v.Hash, &verifiedAt)
+
+	if err == sql.ErrNoRows {
+		cm.stats.recordMiss()
+		return VerifiedFile{}, false
+	}
+	if err != nil {
+		cm.stats.recordError()
+		return VerifiedFile{}, false
+	}
+
+	v.VerifiedAt = verifiedAt.Time
+	cm.stats.recordHit()
+	return v, true
+}
+
+func (cm *Manager) SaveVerifiedFile(v VerifiedFile) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	if v.VerifiedAt.IsZero() {
+		v.VerifiedAt = time.Now()
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`
+		INSERT OR REPLACE INTO verified_files (rom_id, file_name, size, algorithm, hash, verified_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, v.RomID, v.FileName, v.Size, v.Algorithm, v.Hash, v.VerifiedAt)
+	if err != nil {
+		return newCacheError("save", "verified_files", fmt.Sprintf("%d/%s", v.RomID, v.FileName), err)
+	}
+
+	return nil
+}
+
+func (cm *Manager) DeleteVerifiedFiles(romID int) error {
+	if cm == nil || !cm.initialized {
+		return ErrNotInitialized
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, err := cm.db.Exec(`DELETE FROM verified_files WHERE rom_id = ?`, romID)
+	if err != nil {
+		return newCacheError("delete", "verified_files", fmt.Sprintf("%d", romID), err)
+	}
+
+	return nil
+}
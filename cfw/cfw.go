@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -29,8 +30,52 @@ var (
 	SpruceSaveDirectories = mustLoadJSONMap[string, []string]("spruce/save_directories.json")
 
 	KnulliPlatforms = mustLoadJSONMap[string, []string]("knulli/platforms.json")
+
+	// SaveLayouts is shared by every CFW, since it describes emulators rather than folders
+	SaveLayouts = mustLoadJSONMap[string, SaveLayout]("save_layouts.json")
 )
 
+// SaveLayout describes saves that aren't a single file named after the ROM.
+type SaveLayout struct {
+	FolderSaves *FolderSaveLayout `json:"folder_saves,omitempty"`
+	// SharedCards are glob patterns for memory cards that hold saves for many games,
+	// such as PCSX ReARMed's pcsx-card1.mcd or Flycast's vmu_save_A1.bin
+	SharedCards []string `json:"shared_cards,omitempty"`
+	// FirstDiscSaves lists emulator folders whose saves for multi-disc games are named
+	// after the first disc rather than the .m3u playlist
+	FirstDiscSaves []string `json:"first_disc_saves,omitempty"`
+	// Conversions rewrite a downloaded save when it was made by a different emulator
+	Conversions []SaveConversion `json:"conversions,omitempty"`
+}
+
+// SaveConversion applies Steps to saves uploaded from one of the From emulator folders
+// and downloaded into one of the To folders. "*" matches any folder, and an empty
+// Extensions list matches any save file.
+type SaveConversion struct {
+	From       []string         `json:"from"`
+	To         []string         `json:"to"`
+	Extensions []string         `json:"extensions,omitempty"`
+	Steps      []ConversionStep `json:"steps"`
+}
+
+// ConversionStep names a converter registered in the sync package along with its arguments.
+type ConversionStep struct {
+	Converter string `json:"converter"`
+	Ext       string `json:"ext,omitempty"`
+	Size      int    `json:"size,omitempty"`
+	Width     int    `json:"width,omitempty"`
+	Marker    string `json:"marker,omitempty"`
+}
+
+// FolderSaveLayout is used by emulators like PPSSPP that keep each game's save in
+// its own directories, named after the game ID rather than the ROM file.
+type FolderSaveLayout struct {
+	// Roots are tried in order, relative to the emulator save folder
+	Roots []string `json:"roots"`
+	// GameID names the scheme used to read a game ID from the ROM, e.g. "psp"
+	GameID string `json:"game_id"`
+}
+
 const MuOSSD1 = "/mnt/mmc"
 const MuOSSD2 = "/mnt/sdcard"
 const MuOSRomsFolderUnion = "/mnt/union/ROMS"
@@ -144,6 +189,49 @@ func EmulatorFoldersForFSSlug(fsSlug string) []string {
 	return saveDirectoriesMap[fsSlug]
 }
 
+func SaveLayoutForFSSlug(fsSlug string) SaveLayout {
+	return SaveLayouts[fsSlug]
+}
+
+// IsSharedCard reports whether a save file name is one of the platform's shared memory cards.
+func IsSharedCard(fsSlug, fileName string) bool {
+	for _, pattern := range SaveLayouts[fsSlug].SharedCards {
+		if matched, _ := filepath.Match(pattern, fileName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// NamesSavesAfterFirstDisc reports whether an emulator folder names multi-disc saves
+// after disc 1 instead of the .m3u playlist.
+func NamesSavesAfterFirstDisc(fsSlug, emulator string) bool {
+	return slices.Contains(SaveLayouts[fsSlug].FirstDiscSaves, emulator)
+}
+
+// SaveConversionFor returns the first conversion for a save moving between two emulator
+// folders on a platform. Saves moving within the same folder are never converted.
+func SaveConversionFor(fsSlug, from, to, ext string) (SaveConversion, bool) {
+	if from == "" || from == to {
+		return SaveConversion{}, false
+	}
+
+	for _, conversion := range SaveLayouts[fsSlug].Conversions {
+		if matchesEmulator(conversion.From, from) && matchesEmulator(conversion.To, to) &&
+			(len(conversion.Extensions) == 0 || slices.ContainsFunc(conversion.Extensions, func(e string) bool {
+				return strings.EqualFold(e, ext)
+			})) {
+			return conversion, true
+		}
+	}
+
+	return SaveConversion{}, false
+}
+
+func matchesEmulator(folders []string, folder string) bool {
+	return slices.Contains(folders, "*") || slices.Contains(folders, folder)
+}
+
 func RomMFSSlugToCFW(fsSlug string) string {
 	cfwPlatformMap := GetPlatformMap(GetCFW())
 	if cfwPlatformMap == nil {
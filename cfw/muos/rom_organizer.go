@@ -2,6 +2,7 @@ package muos
 
 import (
 	"fmt"
+	"grout/internal/fileutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -52,7 +53,7 @@ func OrganizeMultiFileRom(extractDir, romDirectory, gameName string) error {
 	updatedM3U := strings.Join(lines, "\n")
 
 	m3uDestPath := filepath.Join(romDirectory, gameName+".m3u")
-	if err := os.WriteFile(m3uDestPath, []byte(updatedM3U), 0644); err != nil {
+	if err := fileutil.WriteFileAtomic(m3uDestPath, []byte(updatedM3U), 0644); err != nil {
 		return fmt.Errorf("failed to write updated .m3u file: %w", err)
 	}
 	logger.Debug("Moved and updated .m3u file", "from", m3uFile, "to", m3uDestPath)
@@ -0,0 +1,57 @@
+package download
+
+import (
+	"fmt"
+	"grout/internal/imageutil"
+	"grout/romm"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// FetchArt downloads a game's cover to location and converts it for the frontend.
+func FetchArt(artURL, location string, headers map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(location), 0755); err != nil {
+		return fmt.Errorf("failed to create art directory: %w", err)
+	}
+
+	req, err := http.NewRequest("GET", artURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create art request: %w", err)
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	client := &http.Client{Timeout: romm.DefaultClientTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download art: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("art download failed with bad status: %s", resp.Status)
+	}
+
+	outFile, err := os.Create(location)
+	if err != nil {
+		return fmt.Errorf("failed to create art file: %w", err)
+	}
+
+	_, err = io.Copy(outFile, resp.Body)
+	outFile.Close()
+	if err != nil {
+		os.Remove(location)
+		return fmt.Errorf("failed to write art file: %w", err)
+	}
+
+	if err := imageutil.ProcessArtImage(location); err != nil {
+		os.Remove(location)
+		return fmt.Errorf("failed to process art image: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package download
+
+import (
+	"errors"
+	"grout/cache"
+	"grout/internal"
+	"grout/internal/fileutil"
+	"grout/romm"
+	"io/fs"
+	"path/filepath"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	"go.uber.org/atomic"
+)
+
+// AuditResult is what an audit of the installed games found.
+type AuditResult struct {
+	Checked int
+	Damaged []romm.Rom
+}
+
+// AuditInstalled checks the games Grout installed against RomM's checksums again, to find
+// files damaged on the card since they were downloaded. Files verified before and not
+// changed since are not hashed again. Games extracted from a single archive are skipped,
+// since the archive RomM has the checksum for is gone.
+func AuditInstalled(config internal.Config, progress *atomic.Float64) (AuditResult, error) {
+	logger := gaba.GetLogger()
+	cm := cache.GetCacheManager()
+	var result AuditResult
+
+	installed, err := cm.GetInstalledRoms()
+	if err != nil {
+		return result, err
+	}
+
+	ids := make([]int, 0, len(installed))
+	for id, rom := range installed {
+		if len(rom.ExtractedFiles) == 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	games, err := cm.GetGamesByIDs(ids)
+	if err != nil {
+		return result, err
+	}
+
+	var errs []error
+	for i, game := range games {
+		if progress != nil {
+			progress.Store(float64(i) / float64(len(games)))
+		}
+
+		files := auditFiles(config, game)
+		if len(files) == 0 {
+			continue
+		}
+		result.Checked++
+
+		for _, f := range files {
+			err := verifyFile(game.ID, f.file, f.path)
+			if errors.Is(err, ErrChecksumMismatch) {
+				result.Damaged = append(result.Damaged, game)
+				break
+			}
+			if err != nil {
+				logger.Warn("Unable to verify file", "game", game.Name, "file", f.path, "error", err)
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if progress != nil {
+		progress.Store(1)
+	}
+
+	return result, errors.Join(errs...)
+}
+
+type auditFile struct {
+	file romm.RomFile
+	path string
+}
+
+// auditFiles finds the files of an installed game on the device. The discs of a multi-file
+// game are looked for by name in its folder, since muOS moves some of them out of it;
+// files that can't be found are left out.
+func auditFiles(config internal.Config, game romm.Rom) []auditFile {
+	romDirectory := config.GetPlatformRomDirectory(romPlatform(romm.Platform{}, game))
+
+	if !game.HasMultipleFiles {
+		if len(game.Files) == 0 {
+			return nil
+		}
+		path := filepath.Join(romDirectory, game.Files[0].FileName)
+		if !fileutil.FileExists(path) {
+			return nil
+		}
+		return []auditFile{{singleRomFile(game), path}}
+	}
+
+	byName := make(map[string][]string)
+	for _, dir := range []string{filepath.Join(romDirectory, game.FsNameNoExt), filepath.Join(romDirectory, "_"+game.FsNameNoExt)} {
+		_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() {
+				byName[entry.Name()] = append(byName[entry.Name()], path)
+			}
+			return nil
+		})
+	}
+
+	var files []auditFile
+	for _, file := range game.Files {
+		if paths := byName[file.FileName]; len(paths) == 1 {
+			files = append(files, auditFile{file, paths[0]})
+		}
+	}
+	return files
+}
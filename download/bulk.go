@@ -0,0 +1,277 @@
+package download
+
+import (
+	"cmp"
+	"grout/internal"
+	"grout/internal/archive"
+	"grout/romm"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// BulkFilter decides which games "Download All" queues.
+type BulkFilter struct {
+	NotDownloaded bool
+	// MaxSize skips games larger than this many bytes; 0 means no limit
+	MaxSize int64
+	// Genre keeps only games in this genre; "" keeps every genre
+	Genre string
+	// OneVersion keeps a single version of each game, picked by Regions and Language
+	OneVersion bool
+	Regions    []string
+	Language   string
+}
+
+// BulkSelection is the outcome of applying a BulkFilter, with counts of what was left
+// out and why.
+type BulkSelection struct {
+	Games         []romm.Rom
+	Size          int64
+	Downloaded    int
+	OtherVersions int
+	TooLarge      int
+	OtherGenre    int
+}
+
+// prereleaseTags mark versions that are never preferred over a finished release.
+var prereleaseTags = []string{"beta", "proto", "demo", "sample"}
+
+// SelectBulk applies a filter to a platform's or collection's games. With OneVersion set
+// the versions of a game are grouped through RomM's siblings and shared names, and one is
+// picked: a finished release before a prerelease, then by region priority, then a version
+// in the user's language, then the latest revision.
+func SelectBulk(config internal.Config, platform romm.Platform, games []romm.Rom, filter BulkFilter) BulkSelection {
+	var selection BulkSelection
+
+	kept := make([]romm.Rom, 0, len(games))
+	for _, game := range games {
+		if filter.Genre != "" && !slices.Contains(game.Metadatum.Genres, filter.Genre) {
+			selection.OtherGenre++
+			continue
+		}
+		kept = append(kept, game)
+	}
+
+	groups := make([][]romm.Rom, 0, len(kept))
+	if filter.OneVersion {
+		groups = groupVersions(kept)
+	} else {
+		for _, game := range kept {
+			groups = append(groups, []romm.Rom{game})
+		}
+	}
+
+	installed := newInstalledChecker(config, platform)
+	prefer := preferVersion(filter.Regions, filter.Language)
+
+	for _, group := range groups {
+		if filter.NotDownloaded && slices.ContainsFunc(group, installed.has) {
+			selection.Downloaded++
+			continue
+		}
+
+		fitting := slices.DeleteFunc(slices.Clone(group), func(game romm.Rom) bool {
+			return filter.MaxSize > 0 && int64(game.FsSizeBytes) > filter.MaxSize
+		})
+		if len(fitting) == 0 {
+			selection.TooLarge++
+			continue
+		}
+
+		slices.SortStableFunc(fitting, prefer)
+		selection.Games = append(selection.Games, fitting[0])
+		selection.Size += int64(fitting[0].FsSizeBytes)
+		selection.OtherVersions += len(group) - 1
+	}
+
+	return selection
+}
+
+// groupVersions puts the versions of each game together, in the order the games were
+// first listed. Versions are linked by RomM's siblings and by their name without tags.
+func groupVersions(games []romm.Rom) [][]romm.Rom {
+	parent := make(map[int]int, len(games))
+	var find func(int) int
+	find = func(id int) int {
+		if p, ok := parent[id]; ok && p != id {
+			root := find(p)
+			parent[id] = root
+			return root
+		}
+		parent[id] = id
+		return id
+	}
+	union := func(a, b int) {
+		if ra, rb := find(a), find(b); ra != rb {
+			parent[rb] = ra
+		}
+	}
+
+	byName := make(map[string]int)
+	for _, game := range games {
+		find(game.ID)
+		for _, sibling := range siblingIDs(game) {
+			union(game.ID, sibling)
+		}
+		if game.FsNameNoTags == "" {
+			continue
+		}
+		key := strconv.Itoa(game.PlatformID) + "/" + strings.ToLower(game.FsNameNoTags)
+		if first, ok := byName[key]; ok {
+			union(first, game.ID)
+		} else {
+			byName[key] = game.ID
+		}
+	}
+
+	index := make(map[int]int)
+	var groups [][]romm.Rom
+	for _, game := range games {
+		root := find(game.ID)
+		i, ok := index[root]
+		if !ok {
+			i = len(groups)
+			index[root] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], game)
+	}
+	return groups
+}
+
+// siblingIDs reads the IDs of the other versions RomM lists for a game.
+func siblingIDs(game romm.Rom) []int {
+	var ids []int
+	for _, sibling := range game.Siblings {
+		entry, ok := sibling.(map[string]any)
+		if !ok {
+			continue
+		}
+		if id, ok := entry["id"].(float64); ok && id > 0 {
+			ids = append(ids, int(id))
+		}
+	}
+	return ids
+}
+
+func preferVersion(regions []string, language string) func(a, b romm.Rom) int {
+	return func(a, b romm.Rom) int {
+		if c := cmp.Compare(prereleaseRank(a), prereleaseRank(b)); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(regionRank(regions, a), regionRank(regions, b)); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(languageRank(language, a), languageRank(language, b)); c != 0 {
+			return c
+		}
+		return compareRevision(b.Revision, a.Revision)
+	}
+}
+
+func prereleaseRank(game romm.Rom) int {
+	name := strings.ToLower(game.FsName)
+	for _, tag := range prereleaseTags {
+		if strings.Contains(name, "("+tag) {
+			return 1
+		}
+		for _, t := range game.Tags {
+			if s, ok := t.(string); ok && strings.Contains(strings.ToLower(s), tag) {
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+// regionRank is the position of the game's best region in the priority list, or past
+// the end of it when none of its regions are listed.
+func regionRank(regions []string, game romm.Rom) int {
+	rank := len(regions)
+	for _, region := range game.Regions {
+		if i := slices.IndexFunc(regions, func(r string) bool { return strings.EqualFold(r, region) }); i >= 0 {
+			rank = min(rank, i)
+		}
+	}
+	return rank
+}
+
+// languageRank prefers versions that include the language Grout is shown in. RomM lists
+// languages as codes such as "En" or "Fr".
+func languageRank(language string, game romm.Rom) int {
+	if language == "" {
+		return 0
+	}
+	code := strings.ToLower(language)
+	if len(code) > 2 {
+		code = code[:2]
+	}
+	for _, l := range game.Languages {
+		if strings.HasPrefix(strings.ToLower(l), code) {
+			return 0
+		}
+	}
+	return 1
+}
+
+// compareRevision orders revisions, numerically where both are numbers, with no revision
+// before any.
+func compareRevision(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "" || b == "" {
+		return cmp.Compare(len(a), len(b))
+	}
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return cmp.Compare(na, nb)
+	}
+	return strings.Compare(a, b)
+}
+
+// installedChecker tells whether games are on the device, listing each ROM folder once
+// rather than per game, as InstalledPaths would.
+type installedChecker struct {
+	config   internal.Config
+	platform romm.Platform
+	folders  map[string]map[string]bool
+}
+
+func newInstalledChecker(config internal.Config, platform romm.Platform) *installedChecker {
+	return &installedChecker{
+		config:   config,
+		platform: platform,
+		folders:  make(map[string]map[string]bool),
+	}
+}
+
+func (c *installedChecker) has(game romm.Rom) bool {
+	romDirectory := c.config.GetPlatformRomDirectory(romPlatform(c.platform, game))
+
+	names, ok := c.folders[romDirectory]
+	if !ok {
+		names = make(map[string]bool)
+		if entries, err := os.ReadDir(romDirectory); err == nil {
+			for _, entry := range entries {
+				name := entry.Name()
+				names[name] = true
+				names[strings.TrimSuffix(name, filepath.Ext(name))] = true
+			}
+		}
+		c.folders[romDirectory] = names
+	}
+
+	if game.HasMultipleFiles {
+		return names[game.FsNameNoExt+".m3u"] || names[game.FsNameNoExt] || names["_"+game.FsNameNoExt]
+	}
+	if len(game.Files) == 0 {
+		return false
+	}
+	fileName := game.Files[0].FileName
+	return names[fileName] || (archive.IsArchive(fileName) && names[archive.TrimExt(fileName)])
+}
@@ -0,0 +1,201 @@
+package download
+
+import (
+	"grout/internal"
+	"grout/romm"
+	"slices"
+	"testing"
+)
+
+func TestCompareRevision(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"1", "1", 0},
+		{"", "1", -1},
+		{"1", "", 1},
+		{"2", "10", -1},
+		{"10", "2", 1},
+		{"A", "B", -1},
+		{"B", "A", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := compareRevision(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareRevision(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func gameIDs(games []romm.Rom) []int {
+	ids := make([]int, 0, len(games))
+	for _, game := range games {
+		ids = append(ids, game.ID)
+	}
+	return ids
+}
+
+func TestGroupVersions(t *testing.T) {
+	tests := []struct {
+		name  string
+		games []romm.Rom
+		want  [][]int
+	}{
+		{
+			name: "shared name",
+			games: []romm.Rom{
+				{ID: 1, PlatformID: 1, FsNameNoTags: "Zelda"},
+				{ID: 2, PlatformID: 1, FsNameNoTags: "Mario"},
+				{ID: 3, PlatformID: 1, FsNameNoTags: "zelda"},
+			},
+			want: [][]int{{1, 3}, {2}},
+		},
+		{
+			name: "same name on another platform",
+			games: []romm.Rom{
+				{ID: 1, PlatformID: 1, FsNameNoTags: "Tetris"},
+				{ID: 2, PlatformID: 2, FsNameNoTags: "Tetris"},
+			},
+			want: [][]int{{1}, {2}},
+		},
+		{
+			name: "siblings",
+			games: []romm.Rom{
+				{ID: 1, FsNameNoTags: "Pocket Monsters"},
+				{ID: 2, FsNameNoTags: "Pokemon", Siblings: []any{map[string]any{"id": float64(1)}}},
+			},
+			want: [][]int{{1, 2}},
+		},
+		{
+			name: "siblings join name groups",
+			games: []romm.Rom{
+				{ID: 1, FsNameNoTags: "A"},
+				{ID: 2, FsNameNoTags: "B"},
+				{ID: 3, FsNameNoTags: "A", Siblings: []any{map[string]any{"id": float64(2)}}},
+			},
+			want: [][]int{{1, 2, 3}},
+		},
+		{
+			name: "no name",
+			games: []romm.Rom{
+				{ID: 1},
+				{ID: 2},
+			},
+			want: [][]int{{1}, {2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := groupVersions(tt.games)
+			got := make([][]int, 0, len(groups))
+			for _, group := range groups {
+				got = append(got, gameIDs(group))
+			}
+			if !slices.EqualFunc(got, tt.want, slices.Equal[[]int]) {
+				t.Errorf("groupVersions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectBulk(t *testing.T) {
+	usa := romm.Rom{ID: 1, FsName: "Game (USA).zip", FsNameNoTags: "Game", Regions: []string{"USA"}, Languages: []string{"En"}, FsSizeBytes: 100}
+	europe := romm.Rom{ID: 2, FsName: "Game (Europe).zip", FsNameNoTags: "Game", Regions: []string{"Europe"}, Languages: []string{"En", "Fr"}, FsSizeBytes: 200}
+	japan := romm.Rom{ID: 3, FsName: "Game (Japan).zip", FsNameNoTags: "Game", Regions: []string{"Japan"}, Languages: []string{"Ja"}, FsSizeBytes: 50}
+	beta := romm.Rom{ID: 4, FsName: "Game (USA) (Beta).zip", FsNameNoTags: "Game", Regions: []string{"USA"}, FsSizeBytes: 100}
+	rev1 := romm.Rom{ID: 5, FsName: "Game (USA) (Rev 1).zip", FsNameNoTags: "Game", Regions: []string{"USA"}, Revision: "1", FsSizeBytes: 100}
+	rev2 := romm.Rom{ID: 6, FsName: "Game (USA) (Rev 2).zip", FsNameNoTags: "Game", Regions: []string{"USA"}, Revision: "2", FsSizeBytes: 100}
+	other := romm.Rom{ID: 7, FsName: "Other (USA).zip", FsNameNoTags: "Other", Regions: []string{"USA"}, FsSizeBytes: 300}
+
+	tests := []struct {
+		name   string
+		games  []romm.Rom
+		filter BulkFilter
+		want   []int
+		// wantOther and wantTooLarge are the versions and games left out
+		wantOther    int
+		wantTooLarge int
+	}{
+		{
+			name:   "every version without 1G1R",
+			games:  []romm.Rom{usa, europe, other},
+			filter: BulkFilter{},
+			want:   []int{1, 2, 7},
+		},
+		{
+			name:      "first region in priority",
+			games:     []romm.Rom{japan, europe, usa},
+			filter:    BulkFilter{OneVersion: true, Regions: []string{"Europe", "USA"}},
+			want:      []int{2},
+			wantOther: 2,
+		},
+		{
+			name:      "release before prerelease",
+			games:     []romm.Rom{beta, japan},
+			filter:    BulkFilter{OneVersion: true, Regions: []string{"USA"}},
+			want:      []int{3},
+			wantOther: 1,
+		},
+		{
+			name:      "language breaks a region tie",
+			games:     []romm.Rom{usa, europe},
+			filter:    BulkFilter{OneVersion: true, Language: "fr"},
+			want:      []int{2},
+			wantOther: 1,
+		},
+		{
+			name:      "latest revision",
+			games:     []romm.Rom{rev1, usa, rev2},
+			filter:    BulkFilter{OneVersion: true, Regions: []string{"USA"}},
+			want:      []int{6},
+			wantOther: 2,
+		},
+		{
+			name:      "listed order without preferences",
+			games:     []romm.Rom{japan, usa},
+			filter:    BulkFilter{OneVersion: true},
+			want:      []int{3},
+			wantOther: 1,
+		},
+		{
+			name:         "preferred version too large",
+			games:        []romm.Rom{europe, usa, other},
+			filter:       BulkFilter{OneVersion: true, Regions: []string{"Europe"}, MaxSize: 150},
+			want:         []int{1},
+			wantOther:    1,
+			wantTooLarge: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selection := SelectBulk(internal.Config{}, romm.Platform{}, tt.games, tt.filter)
+			if got := gameIDs(selection.Games); !slices.Equal(got, tt.want) {
+				t.Errorf("SelectBulk() games = %v, want %v", got, tt.want)
+			}
+			if selection.OtherVersions != tt.wantOther {
+				t.Errorf("SelectBulk() other versions = %d, want %d", selection.OtherVersions, tt.wantOther)
+			}
+			if selection.TooLarge != tt.wantTooLarge {
+				t.Errorf("SelectBulk() too large = %d, want %d", selection.TooLarge, tt.wantTooLarge)
+			}
+		})
+	}
+}
+
+func TestSelectBulkGenre(t *testing.T) {
+	games := []romm.Rom{
+		{ID: 1, Metadatum: romm.RomMetadata{Genres: []string{"Platform"}}},
+		{ID: 2, Metadatum: romm.RomMetadata{Genres: []string{"Puzzle"}}},
+	}
+
+	selection := SelectBulk(internal.Config{}, romm.Platform{}, games, BulkFilter{Genre: "Puzzle"})
+	if got := gameIDs(selection.Games); !slices.Equal(got, []int{2}) || selection.OtherGenre != 1 {
+		t.Errorf("SelectBulk() = %v with %d in other genres, want [2] with 1", got, selection.OtherGenre)
+	}
+}
@@ -0,0 +1,145 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"grout/cache"
+	"grout/internal/fileutil"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+const progressSaveInterval = 2 * time.Second
+
+// fetch downloads d to its partial file, resuming with a Range request when some of it
+// is already on disk. The Range carries an If-Range with the validator the partial file
+// was fetched against, so a file changed on RomM since comes back whole and the download
+// starts over. Progress is written to the queue periodically so a crash or power loss
+// only costs the last few seconds.
+func (q *Queue) fetch(ctx context.Context, d cache.QueuedDownload) error {
+	partial := partialPath(d.ID)
+	if err := os.MkdirAll(partialDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	var offset int64
+	if info, err := os.Stat(partial); err == nil {
+		offset = info.Size()
+	}
+
+	// Fully fetched on an earlier attempt that failed while installing
+	if offset > 0 && offset == d.BytesTotal && d.Validator != "" {
+		return nil
+	}
+
+	// Without a validator there is no telling whether the partial file is still current
+	if offset > 0 && d.Validator == "" {
+		os.Remove(partial)
+		offset = 0
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", d.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", q.host.BasicAuthHeader())
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", d.Validator)
+	}
+
+	client := &http.Client{Timeout: q.config.DownloadTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The file changed or the server ignored the range, so start over
+		offset = 0
+		flags |= os.O_TRUNC
+		d.Validator = responseValidator(resp)
+		if err := cache.GetCacheManager().SetQueuedDownloadValidator(d.ID, d.Validator); err != nil {
+			gaba.GetLogger().Warn("DownloadQueue: Unable to save validator", "id", d.ID, "error", err)
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		os.Remove(partial)
+		if offset > 0 {
+			d.BytesTotal, d.Validator = 0, ""
+			return q.fetch(ctx, d)
+		}
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	default:
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	var total int64
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	q.activeBytes.Store(offset)
+	q.activeTotal.Store(total)
+
+	out, err := os.OpenFile(partial, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open partial file: %w", err)
+	}
+	defer out.Close()
+
+	buffer := make([]byte, fileutil.DefaultBufferSize)
+	lastSave := time.Now()
+	for {
+		n, readErr := resp.Body.Read(buffer)
+		if n > 0 {
+			if _, err := out.Write(buffer[:n]); err != nil {
+				return fmt.Errorf("failed to write download: %w", err)
+			}
+			q.activeBytes.Add(int64(n))
+
+			if time.Since(lastSave) >= progressSaveInterval {
+				q.saveProgress(d.ID)
+				lastSave = time.Now()
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return readErr
+		}
+	}
+
+	if err := out.Sync(); err != nil {
+		return fmt.Errorf("failed to flush download: %w", err)
+	}
+
+	if total == 0 {
+		q.activeTotal.Store(q.activeBytes.Load())
+	}
+	q.saveProgress(d.ID)
+
+	return nil
+}
+
+// responseValidator returns what identifies the version of the file being downloaded,
+// for If-Range. Weak ETags can't be used there, so Last-Modified is taken instead.
+func responseValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
@@ -0,0 +1,141 @@
+package download
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"grout/cache"
+	"grout/internal/fileutil"
+	"grout/romm"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// historyExportFile is written next to grout.log, where it can be read off the SD card.
+const historyExportFile = "activity.csv"
+
+func newHistoryEntry(action cache.HistoryAction, game romm.Rom, platform romm.Platform) cache.HistoryEntry {
+	return cache.HistoryEntry{
+		Action:       action,
+		RomID:        game.ID,
+		RomName:      game.Name,
+		PlatformSlug: platform.FSSlug,
+		PlatformName: platform.Name,
+		SizeBytes:    int64(game.FsSizeBytes),
+	}
+}
+
+// recordHistory adds an entry to the device's history, timed from started. Failing to
+// record it never fails what is being recorded.
+func recordHistory(entry cache.HistoryEntry, started time.Time, err error) {
+	entry.Duration = time.Since(started)
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if err := cache.GetCacheManager().AddHistory(entry); err != nil {
+		gaba.GetLogger().Debug("Unable to record history", "game", entry.RomName, "action", entry.Action, "error", err)
+	}
+}
+
+// downloadEntry describes a download of d, as a download or an update of a game already
+// on the device.
+func downloadEntry(d cache.QueuedDownload, update bool) cache.HistoryEntry {
+	action := cache.HistoryDownload
+	if update {
+		action = cache.HistoryUpdate
+	}
+	entry := newHistoryEntry(action, d.Rom, d.Platform)
+	if u, err := url.Parse(d.URL); err == nil {
+		entry.Host = u.Host
+	}
+	return entry
+}
+
+// RecordFailedDownload adds a download that failed before it could be installed to the
+// history.
+func RecordFailedDownload(d cache.QueuedDownload, err error) {
+	_, update := cache.GetCacheManager().GetInstalledRom(d.Rom.ID)
+	recordHistory(downloadEntry(d, update), startedAt(d), err)
+}
+
+func startedAt(d cache.QueuedDownload) time.Time {
+	if d.Started.IsZero() {
+		return time.Now()
+	}
+	return d.Started
+}
+
+// verificationResult describes how a download fared against RomM's checksums. It is empty
+// when the download failed for another reason, before or after it was checked.
+func verificationResult(game romm.Rom, err error) string {
+	switch {
+	case errors.Is(err, ErrChecksumMismatch):
+		return cache.VerificationFailed
+	case err != nil:
+		return ""
+	}
+
+	files := game.Files
+	if !game.HasMultipleFiles && len(files) > 0 {
+		// Single files fall back to the ROM's own checksums, as verifyFile does
+		files = []romm.RomFile{singleRomFile(game)}
+	}
+	if slices.ContainsFunc(files, func(file romm.RomFile) bool {
+		_, _, h := fileChecksum(file)
+		return h != nil
+	}) {
+		return cache.VerificationPassed
+	}
+	return cache.VerificationSkipped
+}
+
+// ExportHistory writes the whole history to a CSV file in Grout's folder and returns its
+// path.
+func ExportHistory() (string, error) {
+	entries, err := cache.GetCacheManager().GetHistory(0)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"time", "action", "game", "rom_id", "platform", "size_bytes", "duration_seconds", "host", "verification", "error"})
+	for _, e := range entries {
+		platform := e.PlatformName
+		if platform == "" {
+			platform = e.PlatformSlug
+		}
+		_ = w.Write([]string{
+			e.At.Local().Format(time.RFC3339),
+			string(e.Action),
+			e.RomName,
+			strconv.Itoa(e.RomID),
+			platform,
+			strconv.FormatInt(e.SizeBytes, 10),
+			strconv.FormatFloat(e.Duration.Seconds(), 'f', 1, 64),
+			e.Host,
+			e.Verification,
+			e.Error,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(wd, historyExportFile)
+	if err := fileutil.WriteFileAtomic(path, buf.Bytes(), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
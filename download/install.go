@@ -0,0 +1,244 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"grout/cache"
+	"grout/cfw"
+	"grout/cfw/muos"
+	"grout/internal"
+	"grout/internal/archive"
+	"grout/romm"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	"go.uber.org/atomic"
+)
+
+// PlanRom works out where a game is fetched from and where it ends up on the device.
+// For multi-file games the location is the folder the archive is extracted into.
+func PlanRom(config internal.Config, host romm.Host, platform romm.Platform, game romm.Rom) cache.QueuedDownload {
+	platform = romPlatform(platform, game)
+	romDirectory := config.GetPlatformRomDirectory(platform)
+
+	d := cache.QueuedDownload{
+		Rom:      game,
+		Platform: platform,
+	}
+
+	if game.HasMultipleFiles {
+		d.Location = filepath.Join(romDirectory, game.FsNameNoExt)
+		d.URL, _ = url.JoinPath(host.URL(), "/api/roms/", strconv.Itoa(game.ID), "content", game.FsName)
+	} else {
+		d.Location = filepath.Join(romDirectory, game.Files[0].FileName)
+		d.URL, _ = url.JoinPath(host.URL(), "/api/roms/", strconv.Itoa(game.ID), "content", game.Files[0].FileName)
+	}
+
+	if config.DownloadArt && (game.PathCoverLarge != "" || game.PathCoverSmall != "" || game.URLCover != "") {
+		var coverPath string
+		if game.PathCoverSmall != "" {
+			coverPath = game.PathCoverSmall
+		} else if game.PathCoverLarge != "" {
+			coverPath = game.PathCoverLarge
+		} else if game.URLCover != "" {
+			coverPath = game.URLCover
+		}
+
+		d.ArtURL = strings.ReplaceAll(host.URL()+coverPath, " ", "%20")
+		d.ArtLocation = filepath.Join(config.GetArtDirectory(platform), game.FsNameNoExt+".png")
+	}
+
+	return d
+}
+
+// romPlatform falls back to the platform recorded on the game when none was chosen, as
+// when browsing a collection.
+func romPlatform(platform romm.Platform, game romm.Rom) romm.Platform {
+	if platform.ID == 0 && game.PlatformID != 0 {
+		return romm.Platform{
+			ID:     game.PlatformID,
+			FSSlug: game.PlatformFSSlug,
+			Name:   game.PlatformDisplayName,
+		}
+	}
+	return platform
+}
+
+// NeedsExtraction reports whether Install will unpack the download rather than move it.
+// Single archives are unpacked when UnzipDownloads is on, unless their platform keeps
+// archives.
+func NeedsExtraction(config internal.Config, d cache.QueuedDownload) bool {
+	if d.Rom.HasMultipleFiles {
+		return true
+	}
+	return config.UnzipDownloads && archive.IsArchive(d.Location) && !config.KeepsArchives(d.Platform.FSSlug)
+}
+
+// Install puts a fetched game in place from the file it was downloaded to. Multi-file
+// archives are extracted and given a playlist where the CFW expects one; single archives
+// are extracted when NeedsExtraction says so, and kept as they are if that fails. Files
+// are checked against RomM's checksums first, and a damaged download is deleted with
+// ErrChecksumMismatch.
+// The installed version is recorded so later changes on RomM show up as updates, and the
+// download and any extraction are added to the history.
+func Install(config internal.Config, d cache.QueuedDownload, staged string, progress *atomic.Float64) error {
+	_, update := cache.GetCacheManager().GetInstalledRom(d.Rom.ID)
+
+	extracted, err := install(config, d, staged, progress)
+	entry := downloadEntry(d, update)
+	entry.Verification = verificationResult(d.Rom, err)
+	recordHistory(entry, startedAt(d), err)
+	if err != nil {
+		return err
+	}
+
+	recordInstall(config, d, extracted)
+	return nil
+}
+
+// install returns the files a single archive was extracted to, relative to the ROM
+// directory, so they and only they are removed with the game.
+func install(config internal.Config, d cache.QueuedDownload, staged string, progress *atomic.Float64) ([]string, error) {
+	logger := gaba.GetLogger()
+	romDirectory := config.GetPlatformRomDirectory(d.Platform)
+
+	if err := cache.GetCacheManager().DeleteVerifiedFiles(d.Rom.ID); err != nil {
+		logger.Debug("Unable to clear earlier verification", "game", d.Rom.Name, "error", err)
+	}
+
+	if d.Rom.HasMultipleFiles {
+		defer os.Remove(staged)
+
+		// The game is extracted and checked next to where it goes, so a failed update
+		// leaves the copy already on the device as it was
+		if err := os.MkdirAll(romDirectory, 0755); err != nil {
+			return nil, err
+		}
+		extractDir, err := os.MkdirTemp(romDirectory, ".grout-install-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(extractDir)
+
+		logger.Debug("Extracting multi-file ROM", "game", d.Rom.DisplayName, "dest", d.Location)
+		if _, err := extract(d, staged, extractDir, progress); err != nil {
+			return nil, fmt.Errorf("failed to extract multi-file ROM: %w", err)
+		}
+
+		if err := verifyExtracted(d.Rom, extractDir); err != nil {
+			return nil, err
+		}
+
+		if err := replaceInstall(config, d, extractDir); err != nil {
+			return nil, fmt.Errorf("failed to move multi-file ROM into place: %w", err)
+		}
+
+		if cfw.GetCFW() == cfw.MuOS {
+			// OrganizeMultiFileRom moves a playlist found in the folder next to it
+			inFolder := filepath.Join(d.Location, d.Rom.FsNameNoExt+".m3u")
+			if _, err := writePlaylist(d.Location, inFolder, "", false); err != nil {
+				logger.Warn("Failed to write playlist", "game", d.Rom.Name, "error", err)
+			}
+			if err := muos.OrganizeMultiFileRom(d.Location, romDirectory, d.Rom.FsNameNoExt); err != nil {
+				os.RemoveAll(d.Location)
+				return nil, fmt.Errorf("failed to organize multi-file ROM for muOS: %w", err)
+			}
+			return nil, nil
+		}
+
+		path, prefix := playlistLocation(romDirectory, d.Rom.FsNameNoExt, d.Rom.FsNameNoExt)
+		if _, err := writePlaylist(d.Location, path, prefix, false); err != nil {
+			logger.Warn("Failed to write playlist", "game", d.Rom.Name, "error", err)
+		}
+
+		return nil, nil
+	}
+
+	if err := verifyFile(d.Rom.ID, singleRomFile(d.Rom), staged); err != nil {
+		if errors.Is(err, ErrChecksumMismatch) {
+			os.Remove(staged)
+		}
+		return nil, err
+	}
+
+	if NeedsExtraction(config, d) {
+		logger.Debug("Extracting single-file ROM", "game", d.Rom.Name, "file", staged)
+		extracted, err := extract(d, staged, romDirectory, progress)
+		if err == nil {
+			if err := os.Remove(staged); err != nil {
+				logger.Warn("Failed to remove archive after extraction", "path", staged, "error", err)
+			}
+			return extracted, nil
+		}
+		logger.Warn("Failed to extract ROM, keeping archive", "game", d.Rom.Name, "error", err)
+		for _, name := range extracted {
+			os.Remove(filepath.Join(romDirectory, name))
+		}
+	}
+
+	if staged == d.Location {
+		return nil, nil
+	}
+
+	return nil, moveFile(staged, d.Location)
+}
+
+// extract unpacks a download and adds the extraction to the history.
+func extract(d cache.QueuedDownload, staged, destDir string, progress *atomic.Float64) ([]string, error) {
+	started := time.Now()
+	extracted, err := archive.Extract(staged, destDir, progress)
+	recordHistory(newHistoryEntry(cache.HistoryExtract, d.Rom, d.Platform), started, err)
+	return extracted, err
+}
+
+// moveFile renames src to dest, copying instead when they are on different filesystems,
+// such as a second SD card.
+func moveFile(src, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(src, dest); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dest + ".part"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Remove(src)
+}
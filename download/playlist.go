@@ -0,0 +1,205 @@
+package download
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"grout/cfw"
+	"grout/internal"
+	"grout/internal/fileutil"
+	"grout/romm"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// discExtensions are the disc image formats a playlist can list, most preferred first. Only
+// the first format found in a folder is used, so a .cue is listed rather than its .bin tracks.
+var discExtensions = []string{".cue", ".chd", ".gdi", ".ccd", ".mds", ".pbp", ".cso", ".iso"}
+
+var discNumberPattern = regexp.MustCompile(`(?i)(?:^|[^a-z])(?:disc|disk|cd)[\s_-]*(\d+)`)
+
+// playlistLocation returns where the CFW expects a multi-disc game's playlist, and what its
+// entries need in front of them to reach the discs in folder. NextUI looks inside the game
+// folder; muOS, Knulli and Spruce list the playlist alongside their other games.
+func playlistLocation(romDirectory, folder, name string) (path, prefix string) {
+	if cfw.GetCFW() == cfw.NextUI {
+		return filepath.Join(romDirectory, folder, name+".m3u"), ""
+	}
+	return filepath.Join(romDirectory, name+".m3u"), folder + "/"
+}
+
+// writePlaylist puts a playlist for the discs in dir at path. A playlist that came with the
+// game is moved there; otherwise one is generated when dir holds two or more discs. It does
+// nothing when path already exists, and reports whether a playlist was written. With strict
+// set, a playlist is only generated when each disc is numbered and no number repeats.
+func writePlaylist(dir, path, prefix string, strict bool) (bool, error) {
+	if fileutil.FileExists(path) {
+		return false, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+
+	var existing string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".m3u") {
+			existing = filepath.Join(dir, entry.Name())
+			break
+		}
+	}
+
+	var discs []string
+	if existing != "" {
+		discs = readPlaylist(existing)
+	} else {
+		discs = findDiscs(entries)
+		if len(discs) < 2 || (strict && !numbered(discs)) {
+			return false, nil
+		}
+	}
+	if len(discs) == 0 {
+		return false, nil
+	}
+
+	var b strings.Builder
+	for _, disc := range discs {
+		b.WriteString(prefix + disc + "\n")
+	}
+	if err := fileutil.WriteFileAtomic(path, []byte(b.String()), 0644); err != nil {
+		return false, err
+	}
+
+	if existing != "" && existing != path {
+		if err := os.Remove(existing); err != nil {
+			gaba.GetLogger().Warn("Failed to remove original playlist", "path", existing, "error", err)
+		}
+	}
+
+	return true, nil
+}
+
+// readPlaylist returns the entries of a playlist as written, skipping blank lines and comments.
+func readPlaylist(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// findDiscs lists the disc images in a folder in disc order, using the file names'
+// "(Disc 1)" or "CD2" markers and falling back to the names themselves.
+func findDiscs(entries []os.DirEntry) []string {
+	for _, ext := range discExtensions {
+		var discs []string
+		for _, entry := range entries {
+			if !entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && strings.EqualFold(filepath.Ext(entry.Name()), ext) {
+				discs = append(discs, entry.Name())
+			}
+		}
+		if len(discs) == 0 {
+			continue
+		}
+
+		slices.SortFunc(discs, func(a, b string) int {
+			if c := cmp.Compare(discNumber(a), discNumber(b)); c != 0 {
+				return c
+			}
+			return cmp.Compare(a, b)
+		})
+		return discs
+	}
+	return nil
+}
+
+// numbered reports whether every disc carries its own disc number.
+func numbered(discs []string) bool {
+	numbers := make(map[int]bool, len(discs))
+	for _, disc := range discs {
+		n := discNumber(disc)
+		if n == 0 || numbers[n] {
+			return false
+		}
+		numbers[n] = true
+	}
+	return true
+}
+
+func discNumber(name string) int {
+	match := discNumberPattern.FindStringSubmatch(name)
+	if match == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(match[1])
+	return n
+}
+
+// RebuildPlaylists writes the playlists missing for multi-disc games already on the device,
+// and moves ones kept inside a game folder to where the CFW looks. Folders that didn't come
+// from a download only get a playlist when every disc is numbered, so a folder of unrelated
+// games is left alone. It returns how many playlists were written.
+func RebuildPlaylists(config internal.Config) (int, error) {
+	logger := gaba.GetLogger()
+	isMuOS := cfw.GetCFW() == cfw.MuOS
+
+	seen := make(map[string]bool)
+	written := 0
+	var errs []error
+
+	for fsSlug := range config.DirectoryMappings {
+		romDirectory := config.GetPlatformRomDirectory(romm.Platform{FSSlug: fsSlug})
+		if seen[romDirectory] {
+			continue
+		}
+		seen[romDirectory] = true
+
+		entries, err := os.ReadDir(romDirectory)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range fileutil.FilterHiddenDirectories(entries) {
+			folder := entry.Name()
+			name := folder
+			if isMuOS {
+				// muOS hides multi-disc games' folders behind an underscore
+				if !strings.HasPrefix(folder, "_") {
+					continue
+				}
+				name = strings.TrimPrefix(folder, "_")
+			}
+
+			path, prefix := playlistLocation(romDirectory, folder, name)
+			ok, err := writePlaylist(filepath.Join(romDirectory, folder), path, prefix, true)
+			if err != nil {
+				logger.Warn("Failed to write playlist", "folder", folder, "error", err)
+				errs = append(errs, err)
+				continue
+			}
+			if ok {
+				logger.Debug("Wrote playlist", "path", path)
+				written++
+			}
+		}
+	}
+
+	return written, errors.Join(errs...)
+}
@@ -0,0 +1,116 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDiscNumber(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"Final Fantasy VII (USA) (Disc 1).cue", 1},
+		{"Final Fantasy VII (USA) (Disc 3).chd", 3},
+		{"Game (Disk 2).iso", 2},
+		{"Game CD2.bin", 2},
+		{"Game_cd_10.chd", 10},
+		{"Game (disc-4).cue", 4},
+		{"Game (USA).cue", 0},
+		{"Discworld (USA).cue", 0},
+		{"Abcd 2.cue", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := discNumber(tt.name); got != tt.want {
+				t.Errorf("discNumber(%q) = %d, want %d", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNumbered(t *testing.T) {
+	tests := []struct {
+		name  string
+		discs []string
+		want  bool
+	}{
+		{"all numbered", []string{"Game (Disc 1).cue", "Game (Disc 2).cue"}, true},
+		{"one unnumbered", []string{"Game (Disc 1).cue", "Other.cue"}, false},
+		{"repeated number", []string{"Game (Disc 1).cue", "Other (Disc 1).cue"}, false},
+		{"none", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := numbered(tt.discs); got != tt.want {
+				t.Errorf("numbered(%v) = %v, want %v", tt.discs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindDiscs(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{
+			name:  "disc order",
+			files: []string{"Game (Disc 10).chd", "Game (Disc 2).chd", "Game (Disc 1).chd"},
+			want:  []string{"Game (Disc 1).chd", "Game (Disc 2).chd", "Game (Disc 10).chd"},
+		},
+		{
+			name:  "cue sheets before their tracks",
+			files: []string{"Game (Disc 1).bin", "Game (Disc 1).cue", "Game (Disc 2).bin", "Game (Disc 2).cue"},
+			want:  []string{"Game (Disc 1).cue", "Game (Disc 2).cue"},
+		},
+		{
+			name:  "preferred format only",
+			files: []string{"Game (Disc 1).chd", "Game (Disc 2).iso"},
+			want:  []string{"Game (Disc 1).chd"},
+		},
+		{
+			name:  "names without numbers",
+			files: []string{"B.iso", "A.iso"},
+			want:  []string{"A.iso", "B.iso"},
+		},
+		{
+			name:  "hidden files and upper case extensions",
+			files: []string{"._Game (Disc 1).cue", "Game (Disc 1).CUE"},
+			want:  []string{"Game (Disc 1).CUE"},
+		},
+		{
+			name:  "no discs",
+			files: []string{"readme.txt", "Game.m3u"},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// A folder named like a disc is never listed
+			if err := os.Mkdir(filepath.Join(dir, "Extras.iso"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := findDiscs(entries); !slices.Equal(got, tt.want) {
+				t.Errorf("findDiscs(%v) = %v, want %v", tt.files, got, tt.want)
+			}
+		})
+	}
+}
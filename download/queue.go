@@ -0,0 +1,440 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"grout/cache"
+	"grout/internal"
+	"grout/romm"
+	"os"
+	"path/filepath"
+	gosync "sync"
+	"sync/atomic"
+	"time"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	icons "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/constants"
+)
+
+// interruption records why the active download was cancelled, so the worker knows
+// whether to keep, park or drop it.
+type interruption int
+
+const (
+	interruptNone interruption = iota
+	interruptPause
+	interruptCancel
+	interruptStop
+)
+
+// Queue downloads ROMs one at a time in the background. The queue lives in the cache
+// database, so downloads left unfinished resume from where they stopped on next launch.
+type Queue struct {
+	host   romm.Host
+	config *internal.Config
+	icon   *gaba.DynamicStatusBarIcon
+
+	mu        gosync.Mutex
+	activeID  int64
+	cancel    context.CancelFunc
+	interrupt interruption
+
+	activeBytes atomic.Int64
+	activeTotal atomic.Int64
+
+	wake     chan struct{}
+	stop     chan struct{}
+	stopOnce gosync.Once
+	done     chan struct{}
+
+	onComplete func(romm.Rom)
+}
+
+func NewQueue(host romm.Host, config *internal.Config) *Queue {
+	return &Queue{
+		host:   host,
+		config: config,
+		icon:   gaba.NewDynamicStatusBarIcon(""),
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+func (q *Queue) Icon() gaba.StatusBarIcon {
+	return gaba.StatusBarIcon{
+		Dynamic: q.icon,
+	}
+}
+
+// OnComplete sets a callback run on the queue's goroutine after each game is installed.
+func (q *Queue) OnComplete(fn func(romm.Rom)) {
+	q.onComplete = fn
+}
+
+// Start resumes any downloads left from a previous run and begins processing the queue.
+func (q *Queue) Start() {
+	if err := cache.GetCacheManager().ResetActiveDownloads(); err != nil {
+		gaba.GetLogger().Warn("DownloadQueue: Unable to reset interrupted downloads", "error", err)
+	}
+	q.removeOrphanedPartials()
+	go q.run()
+}
+
+// Stop interrupts the active download, keeping what has been written so far, and waits
+// for the worker to exit.
+func (q *Queue) Stop() {
+	q.stopOnce.Do(func() {
+		q.interruptActive(0, interruptStop)
+		close(q.stop)
+	})
+	<-q.done
+}
+
+// Plan works out the downloads for games, leaving out any with nothing to download.
+func (q *Queue) Plan(platform romm.Platform, games []romm.Rom) []cache.QueuedDownload {
+	planned := make([]cache.QueuedDownload, 0, len(games))
+	for _, game := range games {
+		if !game.HasMultipleFiles && len(game.Files) == 0 {
+			gaba.GetLogger().Warn("DownloadQueue: Game has no files to download", "game", game.Name)
+			continue
+		}
+		planned = append(planned, PlanRom(*q.config, q.host, platform, game))
+	}
+	return planned
+}
+
+// Enqueue adds planned downloads to the end of the queue and returns how many were added.
+func (q *Queue) Enqueue(downloads []cache.QueuedDownload) int {
+	logger := gaba.GetLogger()
+	cm := cache.GetCacheManager()
+
+	added := 0
+	for _, d := range downloads {
+		if _, err := cm.AddQueuedDownload(d); err != nil {
+			logger.Error("DownloadQueue: Unable to queue download", "game", d.Rom.Name, "error", err)
+			continue
+		}
+		added++
+	}
+
+	if added > 0 {
+		logger.Debug("DownloadQueue: Queued downloads", "count", added)
+		q.signal()
+	}
+
+	return added
+}
+
+// Items returns the queue in order, with live progress for the active download.
+func (q *Queue) Items() []cache.QueuedDownload {
+	items, err := cache.GetCacheManager().GetQueuedDownloads()
+	if err != nil {
+		gaba.GetLogger().Error("DownloadQueue: Unable to read queue", "error", err)
+		return nil
+	}
+
+	q.mu.Lock()
+	activeID := q.activeID
+	q.mu.Unlock()
+
+	for i := range items {
+		if items[i].ID == activeID {
+			items[i].BytesDone = q.activeBytes.Load()
+			items[i].BytesTotal = q.activeTotal.Load()
+		}
+	}
+
+	return items
+}
+
+// Pause parks a download, keeping its partial data so it resumes where it left off.
+func (q *Queue) Pause(id int64) {
+	if q.interruptActive(id, interruptPause) {
+		return
+	}
+	if err := cache.GetCacheManager().SetQueuedDownloadState(id, cache.DownloadPaused, ""); err != nil {
+		gaba.GetLogger().Error("DownloadQueue: Unable to pause download", "id", id, "error", err)
+	}
+	q.refreshIcon()
+}
+
+// Resume returns a paused or failed download to the queue.
+func (q *Queue) Resume(id int64) {
+	if err := cache.GetCacheManager().SetQueuedDownloadState(id, cache.DownloadQueued, ""); err != nil {
+		gaba.GetLogger().Error("DownloadQueue: Unable to resume download", "id", id, "error", err)
+		return
+	}
+	q.signal()
+}
+
+// Cancel removes a download from the queue and deletes its partial data.
+func (q *Queue) Cancel(id int64) {
+	if q.interruptActive(id, interruptCancel) {
+		return
+	}
+	q.remove(id)
+	q.refreshIcon()
+}
+
+// Reorder stores a new queue order. The active download carries on; the order decides
+// what starts next.
+func (q *Queue) Reorder(ids []int64) {
+	if err := cache.GetCacheManager().ReorderQueuedDownloads(ids); err != nil {
+		gaba.GetLogger().Error("DownloadQueue: Unable to reorder queue", "error", err)
+	}
+}
+
+// interruptActive cancels the active download if it matches id, or whatever is active
+// when id is 0. It reports whether a download was interrupted.
+func (q *Queue) interruptActive(id int64, reason interruption) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.cancel == nil || (id != 0 && q.activeID != id) {
+		return false
+	}
+
+	q.interrupt = reason
+	q.cancel()
+	return true
+}
+
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) run() {
+	logger := gaba.GetLogger()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("DownloadQueue: Panic recovered", "panic", r)
+		}
+		close(q.done)
+	}()
+
+	for {
+		next, found := q.next()
+		q.refreshIcon()
+
+		if !found {
+			select {
+			case <-q.wake:
+				continue
+			case <-q.stop:
+				return
+			}
+		}
+
+		q.process(next)
+
+		select {
+		case <-q.stop:
+			return
+		default:
+		}
+	}
+}
+
+func (q *Queue) next() (cache.QueuedDownload, bool) {
+	items, err := cache.GetCacheManager().GetQueuedDownloads()
+	if err != nil {
+		return cache.QueuedDownload{}, false
+	}
+
+	for _, d := range items {
+		if d.State == cache.DownloadQueued {
+			return d, true
+		}
+	}
+
+	return cache.QueuedDownload{}, false
+}
+
+func (q *Queue) process(d cache.QueuedDownload) {
+	logger := gaba.GetLogger()
+	cm := cache.GetCacheManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q.mu.Lock()
+	q.activeID = d.ID
+	q.cancel = cancel
+	q.interrupt = interruptNone
+	q.mu.Unlock()
+
+	q.activeBytes.Store(d.BytesDone)
+	q.activeTotal.Store(d.BytesTotal)
+
+	if err := cm.SetQueuedDownloadState(d.ID, cache.DownloadActive, ""); err != nil {
+		logger.Warn("DownloadQueue: Unable to mark download active", "id", d.ID, "error", err)
+	}
+	q.refreshIcon()
+
+	logger.Debug("DownloadQueue: Downloading", "game", d.Rom.Name, "resumeFrom", d.BytesDone)
+	d.Started = time.Now()
+	err := q.fetchAndInstall(ctx, d)
+
+	q.mu.Lock()
+	reason := q.interrupt
+	q.activeID = 0
+	q.cancel = nil
+	q.mu.Unlock()
+
+	if err != nil && errors.Is(err, context.Canceled) {
+		q.saveProgress(d.ID)
+		switch reason {
+		case interruptPause:
+			logger.Debug("DownloadQueue: Paused", "game", d.Rom.Name)
+			_ = cm.SetQueuedDownloadState(d.ID, cache.DownloadPaused, "")
+		case interruptCancel:
+			logger.Debug("DownloadQueue: Cancelled", "game", d.Rom.Name)
+			q.remove(d.ID)
+		default:
+			_ = cm.SetQueuedDownloadState(d.ID, cache.DownloadQueued, "")
+		}
+		return
+	}
+
+	if err != nil {
+		logger.Error("DownloadQueue: Download failed", "game", d.Rom.Name, "error", err)
+		q.saveProgress(d.ID)
+		failure := cache.FailureError
+		if errors.Is(err, ErrChecksumMismatch) {
+			failure = cache.FailureDamaged
+		}
+		_ = cm.SetQueuedDownloadFailed(d.ID, failure, err.Error())
+		return
+	}
+
+	logger.Info("DownloadQueue: Downloaded", "game", d.Rom.Name)
+	if err := cm.DeleteQueuedDownload(d.ID); err != nil {
+		logger.Warn("DownloadQueue: Unable to remove finished download", "id", d.ID, "error", err)
+	}
+
+	if q.onComplete != nil {
+		q.onComplete(d.Rom)
+	}
+}
+
+// fetchAndInstall downloads and installs d, starting over once if the download turns out
+// to be damaged. A second mismatch is left for the user to retry or cancel.
+func (q *Queue) fetchAndInstall(ctx context.Context, d cache.QueuedDownload) error {
+	err := q.fetch(ctx, d)
+	if err == nil {
+		err = q.install(d)
+	} else if !errors.Is(err, context.Canceled) {
+		RecordFailedDownload(d, err)
+	}
+
+	if errors.Is(err, ErrChecksumMismatch) {
+		gaba.GetLogger().Warn("DownloadQueue: Download damaged, downloading again", "game", d.Rom.Name)
+		os.Remove(partialPath(d.ID))
+		d.BytesDone, d.BytesTotal = 0, 0
+
+		d.Started = time.Now()
+		err = q.fetch(ctx, d)
+		if err == nil {
+			err = q.install(d)
+		} else if !errors.Is(err, context.Canceled) {
+			RecordFailedDownload(d, err)
+		}
+	}
+
+	return err
+}
+
+// install moves a finished download into place and fetches its artwork.
+func (q *Queue) install(d cache.QueuedDownload) error {
+	if err := Install(*q.config, d, partialPath(d.ID), nil); err != nil {
+		return err
+	}
+
+	if d.ArtURL != "" {
+		headers := map[string]string{"Authorization": q.host.BasicAuthHeader()}
+		if err := FetchArt(d.ArtURL, d.ArtLocation, headers); err != nil {
+			gaba.GetLogger().Warn("DownloadQueue: Unable to download art", "game", d.Rom.Name, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (q *Queue) saveProgress(id int64) {
+	if err := cache.GetCacheManager().SetQueuedDownloadProgress(id, q.activeBytes.Load(), q.activeTotal.Load()); err != nil {
+		gaba.GetLogger().Warn("DownloadQueue: Unable to save progress", "id", id, "error", err)
+	}
+}
+
+func (q *Queue) remove(id int64) {
+	if err := cache.GetCacheManager().DeleteQueuedDownload(id); err != nil {
+		gaba.GetLogger().Error("DownloadQueue: Unable to remove download", "id", id, "error", err)
+	}
+	os.Remove(partialPath(id))
+}
+
+// removeOrphanedPartials deletes partial files whose download is no longer queued, such
+// as after logging out cleared the cache database.
+func (q *Queue) removeOrphanedPartials() {
+	entries, err := os.ReadDir(partialDir())
+	if err != nil {
+		return
+	}
+
+	items, err := cache.GetCacheManager().GetQueuedDownloads()
+	if err != nil {
+		return
+	}
+
+	queued := make(map[string]bool, len(items))
+	for _, d := range items {
+		queued[filepath.Base(partialPath(d.ID))] = true
+	}
+
+	for _, entry := range entries {
+		if !queued[entry.Name()] {
+			os.Remove(filepath.Join(partialDir(), entry.Name()))
+		}
+	}
+}
+
+// refreshIcon shows the download icon with the number of games still to fetch, and
+// hides it once nothing is waiting.
+func (q *Queue) refreshIcon() {
+	items, err := cache.GetCacheManager().GetQueuedDownloads()
+	if err != nil {
+		return
+	}
+
+	waiting := 0
+	for _, d := range items {
+		if d.State == cache.DownloadQueued || d.State == cache.DownloadActive {
+			waiting++
+		}
+	}
+
+	if waiting == 0 {
+		q.icon.SetText("")
+		return
+	}
+	q.icon.SetText(fmt.Sprintf("%s %d", icons.Download, waiting))
+}
+
+// partialDir holds downloads in progress. It is kept apart from the temp directory,
+// which is emptied on exit, so partial files are still there to resume.
+func partialDir() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "grout_downloads")
+	}
+	return filepath.Join(wd, ".downloads")
+}
+
+func partialPath(id int64) string {
+	return filepath.Join(partialDir(), fmt.Sprintf("%d.part", id))
+}
@@ -0,0 +1,122 @@
+package download
+
+import (
+	"grout/cache"
+	"grout/internal"
+	"grout/internal/fileutil"
+	"path/filepath"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// extractionRatio estimates how much larger an archived ROM is once extracted. The real size
+// isn't known until the archive is on the device, so this errs on the generous side.
+const extractionRatio = 2
+
+// SpaceShortfall is a filesystem without room for a set of downloads.
+type SpaceShortfall struct {
+	Path   string
+	Needed uint64
+	Free   uint64
+}
+
+// EstimatedSize is the download size of a game as RomM reports it.
+func EstimatedSize(d cache.QueuedDownload) uint64 {
+	if !d.Rom.HasMultipleFiles && len(d.Rom.Files) > 0 && d.Rom.Files[0].FileSizeBytes > 0 {
+		return uint64(d.Rom.Files[0].FileSizeBytes)
+	}
+	return uint64(d.Rom.FsSizeBytes)
+}
+
+// installedSize is the space a game takes once in place. Extracted archives are assumed
+// to grow by extractionRatio, except multi-file games, which RomM zips without compression.
+func installedSize(config internal.Config, d cache.QueuedDownload) uint64 {
+	size := EstimatedSize(d)
+	if !d.Rom.HasMultipleFiles && NeedsExtraction(config, d) {
+		return size * extractionRatio
+	}
+	return size
+}
+
+// CheckQueueSpace checks that the ROM folders and the queue's partial download folder can
+// hold the new downloads on top of everything already queued. Queued downloads run one at
+// a time, so only the largest partial file needs room at once.
+func CheckQueueSpace(config internal.Config, pending, added []cache.QueuedDownload) []SpaceShortfall {
+	downloads := append(append([]cache.QueuedDownload{}, pending...), added...)
+	return checkSpace(config, downloads, func(cache.QueuedDownload) string { return partialDir() }, false)
+}
+
+// CheckDirectSpace checks space for downloads fetched all at once by the download screen.
+// Multi-file archives are staged in the temp folder, zips to be extracted sit in the ROM
+// folder until they are, and everything else is written straight into place.
+func CheckDirectSpace(config internal.Config, downloads []cache.QueuedDownload) []SpaceShortfall {
+	return checkSpace(config, downloads, func(d cache.QueuedDownload) string {
+		switch {
+		case d.Rom.HasMultipleFiles:
+			return fileutil.TempDir()
+		case NeedsExtraction(config, d):
+			return filepath.Dir(d.Location)
+		}
+		return ""
+	}, true)
+}
+
+// checkSpace adds up what each filesystem needs for the installed games plus their
+// staged downloads. stagingDir returns "" for downloads written directly into place.
+func checkSpace(config internal.Config, downloads []cache.QueuedDownload, stagingDir func(cache.QueuedDownload) string, concurrent bool) []SpaceShortfall {
+	type filesystem struct {
+		path      string
+		free      uint64
+		installed uint64
+		staged    uint64
+	}
+
+	logger := gaba.GetLogger()
+	filesystems := make(map[uint64]*filesystem)
+	var order []uint64
+
+	lookup := func(path string) *filesystem {
+		free, device, err := fileutil.FreeSpace(path)
+		if err != nil {
+			logger.Debug("Unable to measure free space", "path", path, "error", err)
+			return nil
+		}
+		if fs, ok := filesystems[device]; ok {
+			return fs
+		}
+		fs := &filesystem{path: path, free: free}
+		filesystems[device] = fs
+		order = append(order, device)
+		return fs
+	}
+
+	for _, d := range downloads {
+		if fs := lookup(d.Location); fs != nil {
+			fs.installed += installedSize(config, d)
+		}
+
+		staging := stagingDir(d)
+		if staging == "" {
+			continue
+		}
+
+		if fs := lookup(staging); fs != nil {
+			size := EstimatedSize(d) - min(EstimatedSize(d), uint64(d.BytesDone))
+			if concurrent {
+				fs.staged += size
+			} else {
+				fs.staged = max(fs.staged, size)
+			}
+		}
+	}
+
+	var shortfalls []SpaceShortfall
+	for _, device := range order {
+		fs := filesystems[device]
+		if needed := fs.installed + fs.staged; needed > fs.free {
+			shortfalls = append(shortfalls, SpaceShortfall{Path: fs.path, Needed: needed, Free: fs.free})
+		}
+	}
+
+	return shortfalls
+}
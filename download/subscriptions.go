@@ -0,0 +1,232 @@
+package download
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"grout/cache"
+	"grout/internal"
+	"grout/romm"
+	"grout/sync"
+	"slices"
+	gosync "sync"
+	"time"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// ErrSubscriptionUnavailable means a subscribed collection isn't in the cache, so what
+// belongs on the device can't be worked out.
+var ErrSubscriptionUnavailable = errors.New("subscribed collection is not cached")
+
+// subscriptionMu keeps a background subscription sync and one started from the menu
+// from working on the device at the same time.
+var subscriptionMu gosync.Mutex
+
+// SubscriptionPlan is what it takes to bring the device in line with its subscribed
+// collections.
+type SubscriptionPlan struct {
+	Add     []romm.Rom
+	AddSize int64
+	Remove  []romm.Rom
+	// OverCap counts games left out because they would take the subscribed games past
+	// the storage cap
+	OverCap int
+}
+
+func (p SubscriptionPlan) IsEmpty() bool {
+	return len(p.Add) == 0 && len(p.Remove) == 0
+}
+
+// SubscriptionResult is what applying a SubscriptionPlan did.
+type SubscriptionResult struct {
+	Queued  int
+	Removed int
+	// NoRoom counts games that weren't queued because the SD card is full
+	NoRoom int
+}
+
+// PlanSubscriptions compares the subscribed collections with what Grout downloaded for
+// them. Games that left every subscribed collection are removed. New games are added in
+// collection order until the storage cap is reached; games the user downloaded or queued
+// themselves are left alone and don't count towards the cap.
+func PlanSubscriptions(config internal.Config, queued []cache.QueuedDownload) (SubscriptionPlan, error) {
+	var plan SubscriptionPlan
+	cm := cache.GetCacheManager()
+
+	owned, err := cm.GetSubscribedRoms()
+	if err != nil {
+		return plan, err
+	}
+
+	var wanted []int
+	if len(config.Subscriptions) > 0 {
+		collections, err := cm.GetCollections()
+		if err != nil {
+			return plan, err
+		}
+		for _, subscription := range config.Subscriptions {
+			i := slices.IndexFunc(collections, subscription.Matches)
+			if i < 0 {
+				return plan, fmt.Errorf("%w: %s", ErrSubscriptionUnavailable, subscription.Name)
+			}
+			for _, id := range collections[i].ROMIDs {
+				if !slices.Contains(wanted, id) {
+					wanted = append(wanted, id)
+				}
+			}
+		}
+	}
+
+	// Membership comes from the collections themselves rather than the games cache, so a
+	// platform that failed to cache can't make its games look removed
+	for id, game := range owned {
+		if !slices.Contains(wanted, id) {
+			plan.Remove = append(plan.Remove, game)
+		}
+	}
+	slices.SortFunc(plan.Remove, func(a, b romm.Rom) int { return cmp.Compare(a.Name, b.Name) })
+
+	games, err := cm.GetGamesByIDs(wanted)
+	if err != nil {
+		return plan, err
+	}
+	slices.SortStableFunc(games, func(a, b romm.Rom) int {
+		return cmp.Compare(slices.Index(wanted, a.ID), slices.Index(wanted, b.ID))
+	})
+
+	isQueued := make(map[int]bool, len(queued))
+	for _, d := range queued {
+		isQueued[d.Rom.ID] = true
+	}
+
+	installed := newInstalledChecker(config, romm.Platform{})
+	var used int64
+	var missing []romm.Rom
+	for _, game := range games {
+		if _, mapped := config.DirectoryMappings[game.PlatformFSSlug]; !mapped {
+			continue
+		}
+		if !game.HasMultipleFiles && len(game.Files) == 0 {
+			continue
+		}
+
+		_, isOwned := owned[game.ID]
+		if installed.has(game) || isQueued[game.ID] {
+			if isOwned {
+				used += int64(game.FsSizeBytes)
+			}
+			continue
+		}
+		missing = append(missing, game)
+	}
+
+	for _, game := range missing {
+		size := int64(game.FsSizeBytes)
+		if config.SubscriptionStorageCap > 0 && used+size > config.SubscriptionStorageCap {
+			plan.OverCap++
+			continue
+		}
+		used += size
+		plan.Add = append(plan.Add, game)
+		plan.AddSize += size
+	}
+
+	return plan, nil
+}
+
+// ApplySubscriptions carries out a plan. Removed games go first, to make room, once their
+// saves are uploaded; if that fails they are kept for the next sync to try again. New
+// games are queued for as long as they fit on the SD card.
+func (q *Queue) ApplySubscriptions(plan SubscriptionPlan) (SubscriptionResult, error) {
+	subscriptionMu.Lock()
+	defer subscriptionMu.Unlock()
+
+	return q.applySubscriptions(plan)
+}
+
+// SyncSubscriptions plans and applies the subscriptions without asking, fetching the
+// collections from RomM first once the cached ones are older than refreshAfter. It does
+// nothing while another subscription sync is running.
+func (q *Queue) SyncSubscriptions(refreshAfter time.Duration) (SubscriptionResult, error) {
+	if !subscriptionMu.TryLock() {
+		return SubscriptionResult{}, nil
+	}
+	defer subscriptionMu.Unlock()
+
+	cm := cache.GetCacheManager()
+	refreshed, err := cm.GetLastRefreshTime(cache.MetaKeyCollectionsRefreshedAt)
+	if len(q.config.Subscriptions) > 0 && (err != nil || time.Since(refreshed) > refreshAfter) {
+		if err := cm.RefreshCollections(); err != nil {
+			gaba.GetLogger().Warn("Subscriptions: Unable to refresh collections", "error", err)
+		}
+	}
+
+	plan, err := PlanSubscriptions(*q.config, q.Items())
+	if err != nil {
+		return SubscriptionResult{}, err
+	}
+	return q.applySubscriptions(plan)
+}
+
+func (q *Queue) applySubscriptions(plan SubscriptionPlan) (SubscriptionResult, error) {
+	logger := gaba.GetLogger()
+	cm := cache.GetCacheManager()
+
+	var result SubscriptionResult
+	var errs []error
+
+	if len(plan.Remove) > 0 {
+		for _, item := range q.Items() {
+			if slices.ContainsFunc(plan.Remove, func(game romm.Rom) bool { return game.ID == item.Rom.ID }) {
+				q.Cancel(item.ID)
+			}
+		}
+
+		var romPaths []string
+		for _, game := range plan.Remove {
+			romPaths = append(romPaths, InstalledPaths(*q.config, romm.Platform{}, game)...)
+		}
+		// Saves stay on the device and go to RomM first, so a game added back later picks up
+		// where it left off. If they can't, the games stay until the next sync plans their
+		// removal again
+		if _, err := sync.UploadGameSaves(q.host, q.config, romPaths); err != nil {
+			logger.Warn("Subscriptions: Unable to upload saves, keeping games for now", "count", len(plan.Remove), "error", err)
+			errs = append(errs, err)
+		} else {
+			for _, game := range plan.Remove {
+				if err := Uninstall(*q.config, romm.Platform{}, game); err != nil {
+					logger.Error("Subscriptions: Unable to remove game", "game", game.Name, "error", err)
+					errs = append(errs, err)
+					continue
+				}
+				if err := cm.DeleteSubscribedRom(game.ID); err != nil {
+					logger.Debug("Subscriptions: Unable to forget removed game", "game", game.Name, "error", err)
+				}
+				result.Removed++
+			}
+		}
+	}
+
+	planned := q.Plan(romm.Platform{}, plan.Add)
+	pending := q.Items()
+	for len(planned) > 0 && len(CheckQueueSpace(*q.config, pending, planned)) > 0 {
+		planned = planned[:len(planned)-1]
+		result.NoRoom++
+	}
+
+	if len(planned) > 0 {
+		games := make([]romm.Rom, 0, len(planned))
+		for _, d := range planned {
+			games = append(games, d.Rom)
+		}
+		if err := cm.SaveSubscribedRoms(games); err != nil {
+			errs = append(errs, err)
+		} else {
+			result.Queued = q.Enqueue(planned)
+		}
+	}
+
+	logger.Info("Subscriptions: Synced", "queued", result.Queued, "removed", result.Removed, "noRoom", result.NoRoom)
+	return result, errors.Join(errs...)
+}
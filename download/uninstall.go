@@ -0,0 +1,129 @@
+package download
+
+import (
+	"errors"
+	"grout/cache"
+	"grout/internal"
+	"grout/internal/fileutil"
+	"grout/romm"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// InstalledPaths lists what a game left on the device when it was downloaded: the ROM
+// file or extracted folder, the playlist and muOS's underscore folder for multi-file
+// games, the files recorded as unpacked from an archived download, and the game's artwork.
+// It is empty when the game isn't on the device.
+func InstalledPaths(config internal.Config, platform romm.Platform, game romm.Rom) []string {
+	var extracted []string
+	if installed, ok := cache.GetCacheManager().GetInstalledRom(game.ID); ok {
+		extracted = installed.ExtractedFiles
+	}
+	return installedPaths(config, platform, game, extracted)
+}
+
+// installedPaths is InstalledPaths for a game whose archive was extracted to the given
+// files, relative to its ROM directory.
+func installedPaths(config internal.Config, platform romm.Platform, game romm.Rom, extracted []string) []string {
+	platform = romPlatform(platform, game)
+	romDirectory := config.GetPlatformRomDirectory(platform)
+
+	var candidates []string
+	if game.HasMultipleFiles {
+		candidates = append(candidates,
+			filepath.Join(romDirectory, game.FsNameNoExt),
+			filepath.Join(romDirectory, "_"+game.FsNameNoExt),
+			filepath.Join(romDirectory, game.FsNameNoExt+".m3u"),
+		)
+	} else if len(game.Files) > 0 {
+		candidates = append(candidates, filepath.Join(romDirectory, game.Files[0].FileName))
+		for _, name := range extracted {
+			if filepath.IsLocal(name) {
+				candidates = append(candidates, filepath.Join(romDirectory, name))
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(candidates)+1)
+	for _, path := range candidates {
+		if fileutil.FileExists(path) && !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+
+	// Artwork alone doesn't make a game installed
+	if len(paths) == 0 {
+		return nil
+	}
+
+	if art := filepath.Join(config.GetArtDirectory(platform), game.FsNameNoExt+".png"); fileutil.FileExists(art) {
+		paths = append(paths, art)
+	}
+	return paths
+}
+
+// InstalledSize is the space a game's files take on the device.
+func InstalledSize(config internal.Config, platform romm.Platform, game romm.Rom) int64 {
+	var size int64
+	for _, path := range InstalledPaths(config, platform, game) {
+		size += fileutil.PathSize(path)
+	}
+	return size
+}
+
+// Uninstall deletes a game's files from the device and adds the removal to the history.
+// Saves are left alone.
+func Uninstall(config internal.Config, platform romm.Platform, game romm.Rom) error {
+	logger := gaba.GetLogger()
+	started := time.Now()
+	entry := newHistoryEntry(cache.HistoryUninstall, game, romPlatform(platform, game))
+
+	romDirectory := config.GetPlatformRomDirectory(romPlatform(platform, game))
+
+	// The history records what was freed on the device rather than the size on RomM
+	var removed int64
+	var errs []error
+	for _, path := range InstalledPaths(config, platform, game) {
+		removed += fileutil.PathSize(path)
+		if err := os.RemoveAll(path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logger.Debug("Removed game file", "game", game.Name, "path", path)
+		removeEmptyParents(path, romDirectory)
+	}
+
+	if err := cache.GetCacheManager().DeleteVerifiedFiles(game.ID); err != nil {
+		logger.Debug("Unable to clear verified files", "game", game.Name, "error", err)
+	}
+
+	entry.SizeBytes = removed
+
+	// The record lists the extracted files, so it is kept until all of them are gone
+	err := errors.Join(errs...)
+	if err == nil {
+		if err := cache.GetCacheManager().DeleteInstalledRom(game.ID); err != nil {
+			logger.Debug("Unable to clear installed record", "game", game.Name, "error", err)
+		}
+	}
+
+	recordHistory(entry, started, err)
+	return err
+}
+
+// removeEmptyParents removes the folders an archive created for a file once they are
+// empty, stopping at the ROM directory.
+func removeEmptyParents(path, romDirectory string) {
+	for dir := filepath.Dir(path); dir != romDirectory; dir = filepath.Dir(dir) {
+		if rel, err := filepath.Rel(romDirectory, dir); err != nil || !filepath.IsLocal(rel) {
+			return
+		}
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package download
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"grout/cache"
+	"grout/internal"
+	"grout/romm"
+	"grout/sync"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// romFingerprint identifies the contents of a game on RomM. It uses the ROM's own hash
+// where RomM has one, and otherwise combines the hashes of its files. The algorithm is
+// kept as a prefix so fingerprints taken with different hashes are never compared.
+func romFingerprint(game romm.Rom) string {
+	switch {
+	case game.Sha1Hash != "":
+		return "sha1:" + strings.ToLower(game.Sha1Hash)
+	case game.Md5Hash != "":
+		return "md5:" + strings.ToLower(game.Md5Hash)
+	case game.CrcHash != "":
+		return "crc32:" + strings.TrimLeft(strings.ToLower(game.CrcHash), "0")
+	}
+
+	var parts []string
+	for _, file := range game.Files {
+		hash := file.Sha1Hash + file.Md5Hash + file.CrcHash
+		if hash == "" {
+			return ""
+		}
+		parts = append(parts, file.FileName+"="+strings.ToLower(hash))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	slices.Sort(parts)
+	sum := sha1.Sum([]byte(strings.Join(parts, "\n")))
+	return "files:" + hex.EncodeToString(sum[:])
+}
+
+func fingerprintAlgorithm(fingerprint string) string {
+	algorithm, _, _ := strings.Cut(fingerprint, ":")
+	return algorithm
+}
+
+// installedFileName is the name a game is downloaded under, and baseName the name its
+// saves are matched by.
+func installedFileName(game romm.Rom) string {
+	if game.HasMultipleFiles || len(game.Files) == 0 {
+		return game.FsName
+	}
+	return game.Files[0].FileName
+}
+
+func baseName(game romm.Rom) string {
+	if game.HasMultipleFiles || len(game.Files) == 0 {
+		return game.FsNameNoExt
+	}
+	fileName := game.Files[0].FileName
+	return strings.TrimSuffix(fileName, filepath.Ext(fileName))
+}
+
+// isOutdated reports whether RomM's copy of a game differs from the one installed. Hashes
+// decide when both sides have them; otherwise only a new revision or file name does. RomM
+// bumps a game's modification time for metadata edits too, so that alone is never taken
+// as an update.
+func isOutdated(installed cache.InstalledRom, game romm.Rom) bool {
+	fingerprint := romFingerprint(game)
+	if fingerprint != "" && installed.Hash != "" && fingerprintAlgorithm(fingerprint) == fingerprintAlgorithm(installed.Hash) {
+		return fingerprint != installed.Hash
+	}
+
+	return game.Revision != installed.Revision || installedFileName(game) != installed.FileName
+}
+
+// OutdatedGames returns the games on the device that have changed on RomM since they
+// were downloaded. Games downloaded before Grout kept track of installs are never listed.
+func OutdatedGames(config internal.Config, platform romm.Platform, games []romm.Rom) []romm.Rom {
+	installed, err := cache.GetCacheManager().GetInstalledRoms()
+	if err != nil || len(installed) == 0 {
+		return nil
+	}
+
+	var outdated []romm.Rom
+	for _, game := range games {
+		record, ok := installed[game.ID]
+		if !ok || !isOutdated(record, game) {
+			continue
+		}
+		if len(InstalledPaths(config, platform, game)) > 0 {
+			outdated = append(outdated, game)
+		}
+	}
+	return outdated
+}
+
+// replaceInstall swaps the folder a multi-file game was extracted to in for any earlier
+// copy, and only then removes the earlier copy along with its muOS folder and playlist,
+// so files dropped from the new version don't linger and muOS can move the folder into
+// place. The earlier copy is put back if the swap fails.
+func replaceInstall(config internal.Config, d cache.QueuedDownload, extractDir string) error {
+	logger := gaba.GetLogger()
+	romDirectory := config.GetPlatformRomDirectory(d.Platform)
+
+	previous := extractDir + "-previous"
+	hadPrevious := false
+	if _, err := os.Stat(d.Location); err == nil {
+		if err := os.Rename(d.Location, previous); err != nil {
+			return err
+		}
+		hadPrevious = true
+	}
+
+	if err := os.Rename(extractDir, d.Location); err != nil {
+		if hadPrevious {
+			_ = os.Rename(previous, d.Location)
+		}
+		return err
+	}
+
+	for _, path := range []string{
+		previous,
+		filepath.Join(romDirectory, "_"+d.Rom.FsNameNoExt),
+		filepath.Join(romDirectory, d.Rom.FsNameNoExt+".m3u"),
+	} {
+		if err := os.RemoveAll(path); err != nil {
+			logger.Warn("Failed to remove previous install", "path", path, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// recordInstall remembers the version of a game just installed and the files extracted
+// from its archive. Files the previous version left behind are deleted, and when an update
+// gave the game a new file name its saves are renamed to match.
+func recordInstall(config internal.Config, d cache.QueuedDownload, extracted []string) {
+	logger := gaba.GetLogger()
+	cm := cache.GetCacheManager()
+
+	newBase := baseName(d.Rom)
+	if previous, ok := cm.GetInstalledRom(d.Rom.ID); ok {
+		old := d.Rom
+		old.FsName = previous.FileName
+		old.FsNameNoExt = previous.BaseName
+		if !old.HasMultipleFiles && len(old.Files) > 0 {
+			old.Files = slices.Clone(old.Files)
+			old.Files[0].FileName = previous.FileName
+		}
+
+		current := installedPaths(config, d.Platform, d.Rom, extracted)
+		for _, path := range installedPaths(config, d.Platform, old, previous.ExtractedFiles) {
+			if slices.Contains(current, path) {
+				continue
+			}
+			if err := os.RemoveAll(path); err != nil {
+				logger.Warn("Failed to remove previous version", "path", path, "error", err)
+			}
+		}
+
+		if previous.BaseName != newBase {
+			if renamed := sync.RenameGameSaves(d.Platform.FSSlug, previous.BaseName, newBase); renamed > 0 {
+				logger.Info("Kept saves for updated game", "game", d.Rom.Name, "saves", renamed)
+			}
+		}
+	}
+
+	err := cm.SaveInstalledRom(cache.InstalledRom{
+		RomID:           d.Rom.ID,
+		PlatformFSSlug:  d.Platform.FSSlug,
+		FileName:        installedFileName(d.Rom),
+		BaseName:        newBase,
+		Revision:        d.Rom.Revision,
+		Hash:            romFingerprint(d.Rom),
+		ServerUpdatedAt: d.Rom.UpdatedAt,
+		ExtractedFiles:  extracted,
+	})
+	if err != nil {
+		logger.Debug("Unable to record installed game", "game", d.Rom.Name, "error", err)
+	}
+}
@@ -0,0 +1,150 @@
+package download
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"grout/cache"
+	"grout/internal/fileutil"
+	"grout/romm"
+	"hash"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	gopath "path"
+	"path/filepath"
+	"strings"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// ErrChecksumMismatch means a download arrived damaged: its contents don't hash to the
+// value RomM has for the file.
+var ErrChecksumMismatch = errors.New("download does not match the checksum on RomM")
+
+// fileChecksum picks the hash to check a file with. CRC32 is preferred because it is
+// enough to catch a damaged transfer and far cheaper than MD5 or SHA-1 on a handheld.
+func fileChecksum(file romm.RomFile) (string, string, hash.Hash) {
+	switch {
+	case file.CrcHash != "":
+		return "crc32", file.CrcHash, crc32.NewIEEE()
+	case file.Md5Hash != "":
+		return "md5", file.Md5Hash, md5.New()
+	case file.Sha1Hash != "":
+		return "sha1", file.Sha1Hash, sha1.New()
+	}
+	return "", "", nil
+}
+
+// sameChecksum compares hex digests, ignoring case and, for CRC32, the leading zeros
+// some RomM versions leave off.
+func sameChecksum(algorithm, a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if algorithm == "crc32" {
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	}
+	return a == b
+}
+
+// verifyFile checks a downloaded file against RomM and records it as verified. Files
+// RomM has no checksum for are accepted as they are, and so are files verified before
+// that haven't changed since.
+func verifyFile(romID int, file romm.RomFile, path string) error {
+	logger := gaba.GetLogger()
+
+	algorithm, expected, h := fileChecksum(file)
+	if h == nil {
+		logger.Debug("No checksum on RomM, skipping verification", "file", file.FileName)
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if v, found := cache.GetCacheManager().GetVerifiedFile(romID, file.FileName); found &&
+		v.Algorithm == algorithm && v.Size == info.Size() && !info.ModTime().After(v.VerifiedAt) {
+		logger.Debug("Already verified", "file", file.FileName, "verifiedAt", v.VerifiedAt)
+		return nil
+	}
+
+	actual, err := fileutil.HashFile(path, h)
+	if err != nil {
+		return fmt.Errorf("failed to hash download: %w", err)
+	}
+
+	if !sameChecksum(algorithm, actual, expected) {
+		logger.Warn("Checksum mismatch", "file", file.FileName, "algorithm", algorithm, "expected", expected, "actual", actual)
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, file.FileName)
+	}
+
+	err = cache.GetCacheManager().SaveVerifiedFile(cache.VerifiedFile{
+		RomID:     romID,
+		FileName:  file.FileName,
+		Size:      info.Size(),
+		Algorithm: algorithm,
+		Hash:      actual,
+	})
+	if err != nil {
+		logger.Debug("Unable to record verified file", "file", file.FileName, "error", err)
+	}
+
+	return nil
+}
+
+// singleRomFile is the file a single-file game downloads as. Older RomM versions only
+// carry the checksums on the ROM itself.
+func singleRomFile(game romm.Rom) romm.RomFile {
+	file := game.Files[0]
+	if file.CrcHash == "" && file.Md5Hash == "" && file.Sha1Hash == "" {
+		file.CrcHash, file.Md5Hash, file.Sha1Hash = game.CrcHash, game.Md5Hash, game.Sha1Hash
+	}
+	return file
+}
+
+// verifyExtracted checks each file of a multi-file game after extraction. Files are found
+// by their path inside the game's folder on RomM, or by name when that is unambiguous.
+func verifyExtracted(game romm.Rom, dir string) error {
+	byPath := make(map[string]string)
+	byName := make(map[string][]string)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if rel, err := filepath.Rel(dir, path); err == nil {
+			byPath[filepath.ToSlash(rel)] = path
+		}
+		byName[entry.Name()] = append(byName[entry.Name()], path)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read extracted files: %w", err)
+	}
+
+	root := gopath.Join(game.FsPath, game.FsName) + "/"
+	for _, file := range game.Files {
+		path, found := byPath[strings.TrimPrefix(file.FullPath, root)]
+		if !found {
+			switch candidates := byName[file.FileName]; len(candidates) {
+			case 0:
+				return fmt.Errorf("%w: %s is missing", ErrChecksumMismatch, file.FileName)
+			case 1:
+				path = candidates[0]
+			default:
+				gaba.GetLogger().Debug("Ambiguous file name, skipping verification", "file", file.FileName)
+				continue
+			}
+		}
+
+		if err := verifyFile(game.ID, file, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
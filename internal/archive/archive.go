@@ -0,0 +1,202 @@
+// Package archive unpacks downloaded ROM archives. Each format is a Format registered
+// by the file name endings it is recognised by, so another can be added without
+// touching the extraction itself. Every decoder is pure Go, as the handhelds have no
+// archive tools to call out to.
+package archive
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"grout/internal/fileutil"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.uber.org/atomic"
+)
+
+var ErrUnsupported = errors.New("unsupported archive format")
+
+// Entry is a file or folder inside an archive. Name uses '/' as the separator.
+type Entry struct {
+	Name string
+	Mode fs.FileMode
+}
+
+// Format reads one kind of archive.
+type Format interface {
+	// Extensions are the lower case file name endings the format is recognised by.
+	Extensions() []string
+	Open(path string) (Reader, error)
+}
+
+// Reader walks the entries of an open archive in order.
+type Reader interface {
+	// Size is the uncompressed size of the archive's contents, or 0 when it isn't known
+	// up front. It drives the progress reported while extracting.
+	Size() uint64
+	// Next moves to the next entry and returns it with its contents, which can only be
+	// read until Next is called again. It returns io.EOF after the last entry.
+	Next() (Entry, io.Reader, error)
+	Close() error
+}
+
+var formats []Format
+
+func init() {
+	Register(zipFormat{})
+	Register(sevenZipFormat{})
+	Register(rarFormat{})
+	Register(tarGzFormat{})
+}
+
+// Register adds a format. Formats registered later win when two claim the same ending.
+func Register(format Format) {
+	formats = append([]Format{format}, formats...)
+}
+
+// find returns the format for a file and the extension it matched. The longest match
+// wins, so "game.tar.gz" is read as a tarball rather than a bare gzip file.
+func find(path string) (Format, string, bool) {
+	name := strings.ToLower(filepath.Base(path))
+
+	var found Format
+	var matched string
+	for _, format := range formats {
+		for _, ext := range format.Extensions() {
+			if strings.HasSuffix(name, ext) && len(ext) > len(matched) {
+				found, matched = format, ext
+			}
+		}
+	}
+	return found, matched, found != nil
+}
+
+// IsArchive reports whether a file is in a format that can be extracted.
+func IsArchive(path string) bool {
+	_, _, ok := find(path)
+	return ok
+}
+
+// TrimExt removes an archive's extension from its name, both parts of it for
+// extensions such as ".tar.gz". Other names are returned unchanged.
+func TrimExt(name string) string {
+	if _, ext, ok := find(name); ok {
+		return name[:len(name)-len(ext)]
+	}
+	return name
+}
+
+// Extract unpacks an archive into destDir, storing the fraction done in progress when it
+// isn't nil. Entries that would land outside destDir and links are refused. It returns the
+// files written, relative to destDir, including those written before an error.
+func Extract(path, destDir string, progress *atomic.Float64) ([]string, error) {
+	format, _, ok := find(path)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, filepath.Base(path))
+	}
+
+	reader, err := format.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer reader.Close()
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	var written []string
+
+	counter := &progressCounter{total: reader.Size(), progress: progress}
+	buffer := make([]byte, fileutil.DefaultBufferSize)
+
+	for {
+		entry, contents, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return written, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		target, err := destination(destDir, entry.Name)
+		if err != nil {
+			return written, err
+		}
+
+		switch {
+		case entry.Mode.IsDir():
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return written, fmt.Errorf("failed to create directory %s: %w", entry.Name, err)
+			}
+		case entry.Mode.IsRegular():
+			rel, _ := filepath.Rel(destDir, target)
+			if !slices.Contains(written, rel) {
+				written = append(written, rel)
+			}
+			if err := writeFile(target, entry.Mode.Perm(), contents, buffer, counter); err != nil {
+				return written, fmt.Errorf("failed to extract file %s: %w", entry.Name, err)
+			}
+		default:
+			return written, fmt.Errorf("refusing to extract %s: not a regular file", entry.Name)
+		}
+	}
+
+	if progress != nil {
+		progress.Store(1)
+	}
+	return written, nil
+}
+
+// destination is where an entry is extracted to, keeping it inside destDir.
+func destination(destDir, name string) (string, error) {
+	target := filepath.Join(destDir, filepath.FromSlash(name))
+	if rel, err := filepath.Rel(destDir, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("refusing to extract %s outside the destination", name)
+	}
+	return target, nil
+}
+
+func writeFile(path string, perm fs.FileMode, contents io.Reader, buffer []byte, counter *progressCounter) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if perm == 0 {
+		perm = 0644
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriterSize(file, fileutil.SmallBufferSize)
+	if _, err := io.CopyBuffer(io.MultiWriter(writer, counter), contents, buffer); err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// progressCounter turns bytes written into the fraction of the archive extracted.
+type progressCounter struct {
+	total    uint64
+	done     uint64
+	progress *atomic.Float64
+}
+
+func (c *progressCounter) Write(p []byte) (int, error) {
+	c.done += uint64(len(p))
+	if c.progress != nil && c.total > 0 {
+		c.progress.Store(min(1, float64(c.done)/float64(c.total)))
+	}
+	return len(p), nil
+}
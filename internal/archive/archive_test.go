@@ -0,0 +1,103 @@
+package archive
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDestination(t *testing.T) {
+	destDir := filepath.Join(t.TempDir(), "roms")
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"game.gba", filepath.Join(destDir, "game.gba"), false},
+		{"Game/Disc 1.cue", filepath.Join(destDir, "Game", "Disc 1.cue"), false},
+		{"Game/../game.gba", filepath.Join(destDir, "game.gba"), false},
+		{"./game.gba", filepath.Join(destDir, "game.gba"), false},
+		{"..game.gba", filepath.Join(destDir, "..game.gba"), false},
+		{"/etc/passwd", filepath.Join(destDir, "etc", "passwd"), false},
+		{"../game.gba", "", true},
+		{"..", "", true},
+		{"Game/../../game.gba", "", true},
+		{"../roms2/game.gba", "", true},
+		{"../../../etc/passwd", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := destination(destDir, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("destination(%q) error = %v, want error %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("destination(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func writeZip(t *testing.T, path string, names ...string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for _, name := range names {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "game.zip")
+	destDir := filepath.Join(dir, "roms")
+	writeZip(t, path, "Game/Disc 1.cue", "Game/Disc 1.bin", "readme.txt")
+
+	extracted, err := Extract(path, destDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{filepath.Join("Game", "Disc 1.cue"), filepath.Join("Game", "Disc 1.bin"), "readme.txt"}
+	if !slices.Equal(extracted, want) {
+		t.Errorf("Extract() = %v, want %v", extracted, want)
+	}
+	for _, name := range want {
+		if _, err := os.Stat(filepath.Join(destDir, name)); err != nil {
+			t.Errorf("%s was not extracted: %v", name, err)
+		}
+	}
+}
+
+func TestExtractRefusesTraversal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "evil.zip")
+	destDir := filepath.Join(dir, "roms")
+	writeZip(t, path, "game.gba", "../evil.gba")
+
+	if _, err := Extract(path, destDir, nil); err == nil {
+		t.Fatal("Extract() of an entry outside the destination succeeded")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil.gba")); !os.IsNotExist(err) {
+		t.Errorf("evil.gba was written outside the destination")
+	}
+}
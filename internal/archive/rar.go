@@ -0,0 +1,51 @@
+package archive
+
+import (
+	"io"
+
+	"github.com/nwaples/rardecode/v2"
+)
+
+type rarFormat struct{}
+
+func (rarFormat) Extensions() []string { return []string{".rar"} }
+
+func (rarFormat) Open(path string) (Reader, error) {
+	// Listing only reads the headers, so sizing the archive first is cheap
+	files, err := rardecode.List(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := rardecode.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var size uint64
+	for _, file := range files {
+		if !file.IsDir && file.UnPackedSize > 0 {
+			size += uint64(file.UnPackedSize)
+		}
+	}
+	return &rarReader{archive: reader, size: size}, nil
+}
+
+type rarReader struct {
+	archive *rardecode.ReadCloser
+	size    uint64
+}
+
+func (r *rarReader) Size() uint64 { return r.size }
+
+func (r *rarReader) Next() (Entry, io.Reader, error) {
+	header, err := r.archive.Next()
+	if err != nil {
+		return Entry{}, nil, err
+	}
+	return Entry{Name: header.Name, Mode: header.Mode()}, r.archive, nil
+}
+
+func (r *rarReader) Close() error {
+	return r.archive.Close()
+}
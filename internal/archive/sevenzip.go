@@ -0,0 +1,69 @@
+package archive
+
+import (
+	"io"
+
+	"github.com/bodgit/sevenzip"
+)
+
+type sevenZipFormat struct{}
+
+func (sevenZipFormat) Extensions() []string { return []string{".7z"} }
+
+func (sevenZipFormat) Open(path string) (Reader, error) {
+	reader, err := sevenzip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	return &sevenZipReader{archive: reader}, nil
+}
+
+// sevenZipReader reads entries in the order they are stored, which lets the decoder
+// carry on through a solid block instead of starting it again for every file.
+type sevenZipReader struct {
+	archive *sevenzip.ReadCloser
+	next    int
+	current io.ReadCloser
+}
+
+func (r *sevenZipReader) Size() uint64 {
+	var size uint64
+	for _, file := range r.archive.File {
+		size += file.UncompressedSize
+	}
+	return size
+}
+
+func (r *sevenZipReader) Next() (Entry, io.Reader, error) {
+	r.closeCurrent()
+	if r.next >= len(r.archive.File) {
+		return Entry{}, nil, io.EOF
+	}
+
+	file := r.archive.File[r.next]
+	r.next++
+
+	entry := Entry{Name: file.Name, Mode: file.FileInfo().Mode()}
+	if entry.Mode.IsDir() {
+		return entry, nil, nil
+	}
+
+	contents, err := file.Open()
+	if err != nil {
+		return Entry{}, nil, err
+	}
+	r.current = contents
+	return entry, contents, nil
+}
+
+func (r *sevenZipReader) closeCurrent() {
+	if r.current != nil {
+		r.current.Close()
+		r.current = nil
+	}
+}
+
+func (r *sevenZipReader) Close() error {
+	r.closeCurrent()
+	return r.archive.Close()
+}
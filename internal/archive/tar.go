@@ -0,0 +1,80 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"os"
+)
+
+type tarGzFormat struct{}
+
+func (tarGzFormat) Extensions() []string { return []string{".tar.gz", ".tgz"} }
+
+func (tarGzFormat) Open(path string) (Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	size := gzipSize(file)
+
+	decompressed, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &tarReader{file: file, gzip: decompressed, tar: tar.NewReader(decompressed), size: size}, nil
+}
+
+// gzipSize reads the uncompressed size gzip stores at the end of the stream. It is only
+// kept modulo 4GB, which is close enough to show progress for ROMs, and counts the tar
+// headers as well as the files.
+func gzipSize(file *os.File) uint64 {
+	var trailer [4]byte
+	info, err := file.Stat()
+	if err != nil || info.Size() < int64(len(trailer)) {
+		return 0
+	}
+	if _, err := file.ReadAt(trailer[:], info.Size()-int64(len(trailer))); err != nil {
+		return 0
+	}
+	return uint64(binary.LittleEndian.Uint32(trailer[:]))
+}
+
+type tarReader struct {
+	file *os.File
+	gzip *gzip.Reader
+	tar  *tar.Reader
+	size uint64
+}
+
+func (r *tarReader) Size() uint64 { return r.size }
+
+func (r *tarReader) Next() (Entry, io.Reader, error) {
+	for {
+		header, err := r.tar.Next()
+		if err != nil {
+			return Entry{}, nil, err
+		}
+
+		// Metadata entries such as pax headers describe the entries around them
+		switch header.Typeflag {
+		case tar.TypeXHeader, tar.TypeXGlobalHeader, tar.TypeGNULongName, tar.TypeGNULongLink:
+			continue
+		}
+
+		mode := header.FileInfo().Mode()
+		if header.Typeflag == tar.TypeDir {
+			mode |= fs.ModeDir
+		}
+		return Entry{Name: header.Name, Mode: mode}, r.tar, nil
+	}
+}
+
+func (r *tarReader) Close() error {
+	r.gzip.Close()
+	return r.file.Close()
+}
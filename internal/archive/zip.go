@@ -0,0 +1,66 @@
+package archive
+
+import (
+	"archive/zip"
+	"io"
+)
+
+type zipFormat struct{}
+
+func (zipFormat) Extensions() []string { return []string{".zip"} }
+
+func (zipFormat) Open(path string) (Reader, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	return &zipReader{archive: reader}, nil
+}
+
+type zipReader struct {
+	archive *zip.ReadCloser
+	next    int
+	current io.ReadCloser
+}
+
+func (r *zipReader) Size() uint64 {
+	var size uint64
+	for _, file := range r.archive.File {
+		size += file.UncompressedSize64
+	}
+	return size
+}
+
+func (r *zipReader) Next() (Entry, io.Reader, error) {
+	r.closeCurrent()
+	if r.next >= len(r.archive.File) {
+		return Entry{}, nil, io.EOF
+	}
+
+	file := r.archive.File[r.next]
+	r.next++
+
+	entry := Entry{Name: file.Name, Mode: file.Mode()}
+	if entry.Mode.IsDir() {
+		return entry, nil, nil
+	}
+
+	contents, err := file.Open()
+	if err != nil {
+		return Entry{}, nil, err
+	}
+	r.current = contents
+	return entry, contents, nil
+}
+
+func (r *zipReader) closeCurrent() {
+	if r.current != nil {
+		r.current.Close()
+		r.current = nil
+	}
+}
+
+func (r *zipReader) Close() error {
+	r.closeCurrent()
+	return r.archive.Close()
+}
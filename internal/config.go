@@ -1,11 +1,18 @@
 package internal
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"grout/cfw"
+	"grout/internal/fileutil"
 	"grout/romm"
+	"io/fs"
 	"os"
+	"slices"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -34,10 +41,37 @@ type Config struct {
 	Language               string                      `json:"language,omitempty"`
 	CollectionView         string                      `json:"collection_view,omitempty"`
 	KidMode                bool                        `json:"kid_mode,omitempty"`
+	DeviceID               string                      `json:"device_id,omitempty"`
+	DeviceName             string                      `json:"device_name,omitempty"`
+	SaveSyncInterval       time.Duration               `json:"save_sync_interval,omitempty"`
+	SaveRetention          int                         `json:"save_retention,omitempty"`
+	SyncExcludedPlatforms  []string                    `json:"sync_excluded_platforms,omitempty"`
+	SyncExcludedGames      []int                       `json:"sync_excluded_games,omitempty"`
+	RegionPriority         []string                    `json:"region_priority,omitempty"`
+	Subscriptions          []CollectionSubscription    `json:"subscriptions,omitempty"`
+	SubscriptionStorageCap int64                       `json:"subscription_storage_cap,omitempty"`
+	KeepArchivePlatforms   []string                    `json:"keep_archive_platforms,omitempty"`
 
 	PlatformOrder []string `json:"platform_order,omitempty"`
 }
 
+// CollectionSubscription is a RomM collection whose games are kept on the device.
+// Virtual collections are named by their VirtualID, the others by ID and kind.
+type CollectionSubscription struct {
+	ID        int    `json:"id,omitempty"`
+	VirtualID string `json:"virtual_id,omitempty"`
+	IsSmart   bool   `json:"is_smart,omitempty"`
+	Name      string `json:"name"`
+}
+
+// Matches reports whether the subscription is for a collection.
+func (s CollectionSubscription) Matches(collection romm.Collection) bool {
+	if collection.IsVirtual || s.VirtualID != "" {
+		return collection.IsVirtual && collection.VirtualID == s.VirtualID
+	}
+	return collection.ID == s.ID && collection.IsSmart == s.IsSmart
+}
+
 type DirectoryMapping struct {
 	RomMSlug     string `json:"slug"`
 	RelativePath string `json:"relative_path"`
@@ -64,18 +98,45 @@ func (c Config) ToLoggable() any {
 		"virtual_collections":     c.ShowVirtualCollections,
 		"downloaded_games_action": c.DownloadedGames,
 		"log_level":               c.LogLevel,
+		"device_id":               c.DeviceID,
+		"device_name":             c.DeviceName,
+		"save_sync_interval":      c.SaveSyncInterval,
+		"save_retention":          c.SaveRetention,
+		"sync_excluded_platforms": c.SyncExcludedPlatforms,
+		"sync_excluded_games":     c.SyncExcludedGames,
+		"region_priority":         c.RegionPriority,
+		"subscriptions":           c.Subscriptions,
+		"subscription_cap":        c.SubscriptionStorageCap,
+		"keep_archive_platforms":  c.KeepArchivePlatforms,
 	}
 }
 
+const (
+	configFile = "config.json"
+	// configBackupFile holds the last config that was saved successfully
+	configBackupFile = "config.json.bak"
+)
+
 func LoadConfig() (*Config, error) {
-	data, err := os.ReadFile("config.json")
+	config, err := readConfigFile(configFile)
 	if err != nil {
-		return nil, fmt.Errorf("reading config.json: %w", err)
-	}
+		// A missing config means Grout hasn't been set up yet; only a damaged one is restored
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("parsing config.json: %w", err)
+		backup, backupErr := readConfigFile(configBackupFile)
+		if backupErr != nil {
+			return nil, err
+		}
+
+		gaba.GetLogger().Warn("Config file is damaged, restoring last known good copy", "error", err)
+		if data, readErr := os.ReadFile(configBackupFile); readErr == nil {
+			if writeErr := fileutil.WriteFileAtomic(configFile, data, 0644); writeErr != nil {
+				gaba.GetLogger().Error("Failed to restore config file", "error", writeErr)
+			}
+		}
+		config = backup
 	}
 
 	if config.ApiTimeout == 0 {
@@ -102,6 +163,20 @@ func LoadConfig() (*Config, error) {
 		config.SaveSyncMode = "off"
 	}
 
+	return config, nil
+}
+
+func readConfigFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
 	return &config, nil
 }
 
@@ -138,14 +213,128 @@ func SaveConfig(config *Config) error {
 		return err
 	}
 
-	if err := os.WriteFile("config.json", pretty, 0644); err != nil {
+	if err := fileutil.WriteFileAtomic(configFile, pretty, 0644); err != nil {
 		gaba.GetLogger().Error("Failed to write config file", "error", err)
 		return err
 	}
 
+	if err := fileutil.WriteFileAtomic(configBackupFile, pretty, 0644); err != nil {
+		gaba.GetLogger().Warn("Failed to write config backup", "error", err)
+	}
+
 	return nil
 }
 
+// EnsureDeviceIdentity assigns a random device ID and a default device name the first time
+// they are needed. Returns true if the config changed and should be saved.
+func (c *Config) EnsureDeviceIdentity() bool {
+	changed := false
+
+	if c.DeviceID == "" {
+		id := make([]byte, 4)
+		if _, err := rand.Read(id); err != nil {
+			gaba.GetLogger().Error("Failed to generate device ID", "error", err)
+		} else {
+			c.DeviceID = hex.EncodeToString(id)
+			changed = true
+		}
+	}
+
+	if c.DeviceName == "" {
+		c.DeviceName = defaultDeviceName()
+		changed = true
+	}
+
+	return changed
+}
+
+// IsPlatformSyncExcluded reports whether saves for a platform are left out of save sync.
+func (c Config) IsPlatformSyncExcluded(fsSlug string) bool {
+	return slices.Contains(c.SyncExcludedPlatforms, fsSlug)
+}
+
+// IsGameSyncExcluded reports whether a single game's saves are left out of save sync.
+func (c Config) IsGameSyncExcluded(romID int) bool {
+	return romID > 0 && slices.Contains(c.SyncExcludedGames, romID)
+}
+
+// SetGameSyncExcluded adds or removes a game from the save sync exclusions.
+func (c *Config) SetGameSyncExcluded(romID int, excluded bool) {
+	c.SyncExcludedGames = slices.DeleteFunc(c.SyncExcludedGames, func(id int) bool { return id == romID })
+	if excluded {
+		c.SyncExcludedGames = append(c.SyncExcludedGames, romID)
+	}
+}
+
+// SetPlatformSyncExcluded adds or removes a platform from the save sync exclusions.
+func (c *Config) SetPlatformSyncExcluded(fsSlug string, excluded bool) {
+	c.SyncExcludedPlatforms = slices.DeleteFunc(c.SyncExcludedPlatforms, func(s string) bool { return s == fsSlug })
+	if excluded {
+		c.SyncExcludedPlatforms = append(c.SyncExcludedPlatforms, fsSlug)
+	}
+}
+
+// ArchivePlatforms are the platforms whose emulators load ROM sets from the zip itself,
+// as arcade and FBNeo cores do. Their downloads are never extracted.
+var ArchivePlatforms = []string{"arcade", "fbneo", "mame", "cps1", "cps2", "cps3", "neogeoaes", "neogeomvs", "naomi"}
+
+// KeepsArchives reports whether a platform's downloads are left archived even when
+// archives are being extracted.
+func (c Config) KeepsArchives(fsSlug string) bool {
+	return slices.Contains(ArchivePlatforms, fsSlug) || slices.Contains(c.KeepArchivePlatforms, fsSlug)
+}
+
+// SetKeepArchives sets whether a platform's downloads are left archived.
+func (c *Config) SetKeepArchives(fsSlug string, keep bool) {
+	c.KeepArchivePlatforms = slices.DeleteFunc(c.KeepArchivePlatforms, func(s string) bool { return s == fsSlug })
+	if keep {
+		c.KeepArchivePlatforms = append(c.KeepArchivePlatforms, fsSlug)
+	}
+}
+
+// DefaultRegionPriority is the order regions are preferred in when only one version of
+// each game is downloaded.
+var DefaultRegionPriority = []string{"USA", "World", "Europe", "Japan"}
+
+// Regions returns the region priority, most preferred first.
+func (c Config) Regions() []string {
+	if len(c.RegionPriority) == 0 {
+		return DefaultRegionPriority
+	}
+	return c.RegionPriority
+}
+
+// SetPreferredRegion moves a region to the front of the region priority.
+func (c *Config) SetPreferredRegion(region string) {
+	regions := slices.DeleteFunc(slices.Clone(c.Regions()), func(r string) bool { return strings.EqualFold(r, region) })
+	c.RegionPriority = append([]string{region}, regions...)
+}
+
+// IsSubscribed reports whether the device keeps a collection's games installed.
+func (c Config) IsSubscribed(collection romm.Collection) bool {
+	return slices.ContainsFunc(c.Subscriptions, func(s CollectionSubscription) bool { return s.Matches(collection) })
+}
+
+// SetSubscribed subscribes the device to a collection or drops the subscription.
+func (c *Config) SetSubscribed(collection romm.Collection, subscribed bool) {
+	c.Subscriptions = slices.DeleteFunc(c.Subscriptions, func(s CollectionSubscription) bool { return s.Matches(collection) })
+	if subscribed {
+		c.Subscriptions = append(c.Subscriptions, CollectionSubscription{
+			ID:        collection.ID,
+			VirtualID: collection.VirtualID,
+			IsSmart:   collection.IsSmart,
+			Name:      collection.Name,
+		})
+	}
+}
+
+func defaultDeviceName() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" && hostname != "localhost" {
+		return hostname
+	}
+	return string(cfw.GetCFW())
+}
+
 // SortPlatformsByOrder sorts platforms based on the saved order in config.
 // If no order is saved, platforms are sorted alphabetically.
 func SortPlatformsByOrder(platforms []romm.Platform, order []string) []romm.Platform {
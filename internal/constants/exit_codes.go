@@ -19,6 +19,18 @@ const (
 	ExitCodeGameOptions              gaba.ExitCode = 113
 	ExitCodeGeneralSettings          gaba.ExitCode = 114
 	ExitCodeCheckUpdate              gaba.ExitCode = 115
+	ExitCodeRestoreSaves             gaba.ExitCode = 116
+	ExitCodeCleanUpSaves             gaba.ExitCode = 117
+	ExitCodeDownloadQueue            gaba.ExitCode = 118
+	ExitCodeRemoveGames              gaba.ExitCode = 119
+	ExitCodeUpdateGames              gaba.ExitCode = 120
+	ExitCodeDownloadAll              gaba.ExitCode = 121
+	ExitCodeToggleSubscription       gaba.ExitCode = 122
+	ExitCodeSyncSubscriptions        gaba.ExitCode = 123
+	ExitCodeExtractionSettings       gaba.ExitCode = 124
+	ExitCodeRebuildPlaylists         gaba.ExitCode = 125
+	ExitCodeActivity                 gaba.ExitCode = 126
+	ExitCodeVerifyGames              gaba.ExitCode = 127
 	ExitCodeSearch                   gaba.ExitCode = 200
 	ExitCodeClearSearch              gaba.ExitCode = 201
 	ExitCodeCollections              gaba.ExitCode = 300
@@ -7,4 +7,5 @@ const (
 	UpdaterTimeout     = 10 * time.Minute
 	LoginTimeout       = 6 * time.Second // Timeout for login attempts
 	ValidationTimeout  = 3 * time.Second // Fast timeout for pre-flight connection checks
+	GameSyncTimeout    = 4 * time.Second // Keeps launch-time save sync from delaying the game
 )
@@ -1,15 +1,14 @@
 package fileutil
 
 import (
-	"archive/zip"
-	"bufio"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
-
-	"go.uber.org/atomic"
 )
 
 const (
@@ -17,22 +16,6 @@ const (
 	SmallBufferSize   = 32 * 1024  // 32KB
 )
 
-type progressWriter struct {
-	writer         io.Writer
-	totalBytes     uint64
-	extractedBytes *uint64
-	progress       *atomic.Float64
-}
-
-func (pw *progressWriter) Write(p []byte) (int, error) {
-	n, err := pw.writer.Write(p)
-	if n > 0 && pw.progress != nil && pw.totalBytes > 0 {
-		*pw.extractedBytes += uint64(n)
-		pw.progress.Store(float64(*pw.extractedBytes) / float64(pw.totalBytes))
-	}
-	return n, err
-}
-
 func TempDir() string {
 	wd, err := os.Getwd()
 	if err != nil {
@@ -71,98 +54,95 @@ func CopyFile(src, dest string) error {
 	return nil
 }
 
-func DeleteFile(path string) error {
-	return os.Remove(path)
-}
+// WriteFileAtomic replaces the file at path with data without ever leaving a truncated
+// file behind. The data is written and synced to a temp file in the same directory, renamed
+// over path, and the directory is synced so the rename itself survives a power loss.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
 
-func Unzip(zipPath string, destDir string, progress *atomic.Float64) error {
-	reader, err := zip.OpenReader(zipPath)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
 	if err != nil {
-		return fmt.Errorf("failed to open zip file: %w", err)
-	}
-	defer reader.Close()
-
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return fmt.Errorf("failed to create destination directory: %w", err)
+		return fmt.Errorf("failed to create temp file: %w", err)
 	}
+	tmpPath := tmp.Name()
 
-	buffer := make([]byte, DefaultBufferSize)
-
-	createdDirs := make(map[string]bool)
-	createdDirs[destDir] = true
-
-	var totalBytes uint64
-	for _, file := range reader.File {
-		if !file.FileInfo().IsDir() {
-			totalBytes += file.UncompressedSize64
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
 		}
-	}
-
-	var extractedBytes uint64
-
-	for _, file := range reader.File {
-		filePath := filepath.Join(destDir, file.Name)
+	}()
 
-		if file.FileInfo().IsDir() {
-			if !createdDirs[filePath] {
-				if err := os.MkdirAll(filePath, file.Mode()); err != nil {
-					return fmt.Errorf("failed to create directory %s: %w", filePath, err)
-				}
-				createdDirs[filePath] = true
-			}
-			continue
-		}
-
-		parentDir := filepath.Dir(filePath)
-		if !createdDirs[parentDir] {
-			if err := os.MkdirAll(parentDir, 0755); err != nil {
-				return fmt.Errorf("failed to create parent directory for %s: %w", filePath, err)
-			}
-			createdDirs[parentDir] = true
-		}
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	// FAT and exFAT SD cards have no permissions and may reject this
+	_ = tmp.Chmod(perm)
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
 
-		if err := extractFile(file, filePath, buffer, totalBytes, &extractedBytes, progress); err != nil {
-			return fmt.Errorf("failed to extract file %s: %w", file.Name, err)
-		}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", filepath.Base(path), err)
 	}
+	committed = true
 
+	syncDir(dir)
 	return nil
 }
 
-func FileExists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil
+// syncDir flushes a directory entry to disk. It is best effort, since some filesystems
+// used on SD cards don't support syncing a directory.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
 }
 
-func extractFile(file *zip.File, destPath string, buffer []byte, totalBytes uint64, extractedBytes *uint64, progress *atomic.Float64) error {
-	srcFile, err := file.Open()
+// HashFile streams the file at path through h and returns the hex-encoded digest.
+func HashFile(path string, h hash.Hash) (string, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("failed to open file for hashing: %w", err)
 	}
-	defer srcFile.Close()
+	defer f.Close()
 
-	destFile, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.Mode())
-	if err != nil {
-		return err
+	if _, err := io.CopyBuffer(h, f, make([]byte, SmallBufferSize)); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
 	}
-	defer destFile.Close()
 
-	bufWriter := bufio.NewWriterSize(destFile, SmallBufferSize)
-	defer bufWriter.Flush()
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
 
-	progressW := &progressWriter{
-		writer:         bufWriter,
-		totalBytes:     totalBytes,
-		extractedBytes: extractedBytes,
-		progress:       progress,
-	}
+func DeleteFile(path string) error {
+	return os.Remove(path)
+}
 
-	_, err = io.CopyBuffer(progressW, srcFile, buffer)
-	if err != nil {
-		return err
-	}
+func FileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
 
-	return bufWriter.Flush()
+// PathSize returns the size of a file, or of everything inside a directory.
+func PathSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
 }
 
 func FilterVisibleFiles(entries []os.DirEntry) []os.DirEntry {
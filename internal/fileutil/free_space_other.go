@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package fileutil
+
+import "errors"
+
+// FreeSpace is unavailable on this platform, so space checks are skipped.
+func FreeSpace(path string) (uint64, uint64, error) {
+	return 0, 0, errors.ErrUnsupported
+}
@@ -0,0 +1,42 @@
+//go:build linux || darwin
+
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// FreeSpace returns the bytes available on the filesystem holding path, along with an ID
+// for that filesystem so callers can tell when two directories share one. A path that
+// doesn't exist yet is measured at its nearest existing parent.
+func FreeSpace(path string) (uint64, uint64, error) {
+	dir := existingParent(path)
+
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(dir, &fs); err != nil {
+		return 0, 0, err
+	}
+
+	var st syscall.Stat_t
+	if err := syscall.Stat(dir, &st); err != nil {
+		return 0, 0, err
+	}
+
+	return uint64(fs.Bavail) * uint64(fs.Bsize), uint64(st.Dev), nil
+}
+
+func existingParent(path string) string {
+	dir := filepath.Clean(path)
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
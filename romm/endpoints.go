@@ -19,5 +19,6 @@ const (
 
 	endpointFirmware = "/api/firmware"
 
-	endpointSaves = "/api/saves"
+	endpointSaves       = "/api/saves"
+	endpointSavesDelete = "/api/saves/delete"
 )
@@ -94,3 +94,13 @@ func (c *Client) UploadSave(romID int, savePath string, emulator string) (Save,
 
 	return res, nil
 }
+
+// DeleteSaves removes saves from RomM, including their files on the server.
+func (c *Client) DeleteSaves(saveIDs []int) error {
+	if len(saveIDs) == 0 {
+		return nil
+	}
+	// RomM only removes the files of the saves also listed in delete_from_fs
+	body := map[string][]int{"saves": saveIDs, "delete_from_fs": saveIDs}
+	return c.doRequest("POST", endpointSavesDelete, nil, body, nil)
+}
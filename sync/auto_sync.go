@@ -3,6 +3,7 @@ package sync
 import (
 	"grout/internal"
 	"grout/romm"
+	gosync "sync"
 	"sync/atomic"
 
 	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
@@ -13,9 +14,14 @@ type AutoSync struct {
 	host       romm.Host
 	config     *internal.Config
 	icon       *gaba.DynamicStatusBarIcon
-	running    atomic.Bool
-	done       chan struct{}
 	showButton atomic.Bool
+	onComplete func()
+
+	// mu guards starting and finishing a run, and with it done and rerun
+	mu      gosync.Mutex
+	running atomic.Bool
+	done    chan struct{}
+	rerun   bool
 }
 
 func NewAutoSync(host romm.Host, config *internal.Config) *AutoSync {
@@ -34,9 +40,7 @@ func (a *AutoSync) Icon() gaba.StatusBarIcon {
 }
 
 func (a *AutoSync) Start() {
-	a.running.Store(true)
-	a.done = make(chan struct{}) // Reinitialize channel for reuse
-	go a.run()
+	a.Trigger()
 }
 
 func (a *AutoSync) IsRunning() bool {
@@ -44,7 +48,10 @@ func (a *AutoSync) IsRunning() bool {
 }
 
 func (a *AutoSync) Wait() {
-	<-a.done
+	a.mu.Lock()
+	done := a.done
+	a.mu.Unlock()
+	<-done
 }
 
 func (a *AutoSync) ShowButton() *atomic.Bool {
@@ -54,39 +61,101 @@ func (a *AutoSync) ShowButton() *atomic.Bool {
 // Trigger starts a new sync if one isn't already running.
 // Returns true if a new sync was started, false if one is already in progress.
 func (a *AutoSync) Trigger() bool {
+	// The scheduler and the sync button can race here, so claim the run under the lock
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	if a.running.Load() {
 		return false
 	}
-	a.Start()
+	a.running.Store(true)
+	a.rerun = false
+	a.done = make(chan struct{})
+	go a.run()
+	return true
+}
+
+// RequestRerun asks a running sync to run once more when it ends, for saves that changed
+// after it scanned. It returns false when no sync is running.
+func (a *AutoSync) RequestRerun() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.running.Load() {
+		return false
+	}
+	a.rerun = true
 	return true
 }
 
+// OnComplete sets a callback run at the end of each sync, before the sync counts as
+// finished, so anything it does is waited for on exit as well.
+func (a *AutoSync) OnComplete(fn func()) {
+	a.onComplete = fn
+}
+
 func (a *AutoSync) Host() romm.Host {
 	return a.host
 }
 
+// run syncs until no rerun was requested during the last pass, then marks the sync as
+// finished.
 func (a *AutoSync) run() {
+	for {
+		a.sync()
+
+		a.mu.Lock()
+		if !a.rerun {
+			a.running.Store(false)
+			close(a.done)
+			a.mu.Unlock()
+			return
+		}
+		a.rerun = false
+		a.mu.Unlock()
+
+		gaba.GetLogger().Debug("AutoSync: Saves changed during sync, running again")
+	}
+}
+
+func (a *AutoSync) sync() {
 	logger := gaba.GetLogger()
 	defer func() {
 		if r := recover(); r != nil {
 			logger.Error("AutoSync: Panic recovered", "panic", r)
 			a.icon.SetText(icons.CloudAlert)
 		}
-		a.running.Store(false)
-		close(a.done)
+	}()
+	defer func() {
+		if a.onComplete != nil {
+			a.onComplete()
+		}
 	}()
 
 	a.icon.SetText(icons.CloudRefresh)
 	logger.Debug("AutoSync: Starting save sync scan")
 
-	syncs, _, err := FindSaveSyncs(a.host, a.config)
+	hadError := false
+	hadConflict := false
+
+	// Retry earlier failures first, so the scan below sees their results
+	for _, result := range RetryQueuedSyncs(a.host, a.config) {
+		if !result.Success {
+			logger.Error("AutoSync: Queued sync failed again", "game", result.GameName, "error", result.Error)
+			hadError = true
+		}
+	}
+
+	scan := ScanRoms()
+	syncs, _, err := FindSaveSyncsFromScan(a.host, a.config, scan)
 	if err != nil {
 		logger.Error("AutoSync: Failed to find save syncs", "error", err)
+		if queued := queueChangedSaves(scan, err.Error()); queued > 0 {
+			logger.Info("AutoSync: Queued changed saves for upload", "count", queued)
+		}
 		a.icon.SetText(icons.CloudAlert)
 		return
 	}
 
-	if len(syncs) == 0 {
+	if len(syncs) == 0 && !hadError {
 		a.icon.SetText(icons.CloudCheck)
 		logger.Debug("AutoSync: No syncs needed")
 		return
@@ -94,10 +163,12 @@ func (a *AutoSync) run() {
 		logger.Debug("AutoSync: Found syncs", "count", len(syncs))
 	}
 
-	hadError := false
-
 	for i := range syncs {
 		s := &syncs[i]
+		if waitingForRetry(*s) {
+			logger.Debug("AutoSync: Leaving sync to the retry queue", "game", s.GameBase)
+			continue
+		}
 
 		switch s.Action {
 		case Upload:
@@ -106,6 +177,9 @@ func (a *AutoSync) run() {
 		case Download:
 			a.icon.SetText(icons.CloudDownload)
 			logger.Debug("AutoSync: Downloading", "game", s.GameBase)
+		case Conflict:
+			hadConflict = true
+			logger.Info("AutoSync: Shared card changed on both sides, keeping a copy of the RomM card", "card", s.GameBase)
 		case Skip:
 			continue
 		}
@@ -119,9 +193,9 @@ func (a *AutoSync) run() {
 		}
 	}
 
-	if hadError {
+	if hadError || hadConflict {
 		a.icon.SetText(icons.CloudAlert)
-		logger.Debug("AutoSync: Completed with errors")
+		logger.Debug("AutoSync: Completed with errors or conflicts")
 	} else {
 		a.icon.SetText(icons.CloudCheck)
 		logger.Debug("AutoSync: Completed successfully")
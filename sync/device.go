@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"grout/internal"
+	"grout/romm"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Uploaded saves carry a "[<device name>#<device id>]" tag in their filename so that
+// other devices can tell where a save came from.
+const deviceTagSeparator = "#"
+
+var deviceTagPattern = regexp.MustCompile(`\[([^\[\]#]+)#([0-9a-f]{8})\]`)
+
+var saveTagPattern = regexp.MustCompile(`\s*\[[^\[\]]*\]`)
+
+func deviceTag(config *internal.Config) string {
+	if config == nil || config.DeviceID == "" {
+		return ""
+	}
+
+	name := sanitizeDeviceName(config.DeviceName)
+	if name == "" {
+		name = config.DeviceID
+	}
+
+	return "[" + name + deviceTagSeparator + config.DeviceID + "]"
+}
+
+func sanitizeDeviceName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '[', ']', '#', '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return -1
+		}
+		return r
+	}, name)
+	return strings.TrimSpace(name)
+}
+
+// ParseDeviceTag extracts the device name and ID from an uploaded save filename.
+// Both are empty if the save was not uploaded by Grout or predates device tagging.
+func ParseDeviceTag(fileName string) (name string, id string) {
+	matches := deviceTagPattern.FindAllStringSubmatch(filepath.Base(fileName), -1)
+	if len(matches) == 0 {
+		return "", ""
+	}
+	last := matches[len(matches)-1]
+	return strings.TrimSpace(last[1]), last[2]
+}
+
+// SaveDeviceName returns the name of the device that uploaded a remote save, if known.
+func SaveDeviceName(save romm.Save) string {
+	name, _ := ParseDeviceTag(save.FileName)
+	return name
+}
+
+// IsFromDevice reports whether a remote save was uploaded by the device with the given ID.
+func IsFromDevice(save romm.Save, deviceID string) bool {
+	_, id := ParseDeviceTag(save.FileName)
+	return id != "" && id == deviceID
+}
+
+// untaggedSaveName strips the timestamp and device tags Grout adds on upload, turning
+// "pcsx-card1 [2024-05-01 10-00-00-000] [Brick#1a2b3c4d].mcd" back into "pcsx-card1.mcd".
+func untaggedSaveName(save romm.Save) string {
+	name := saveTagPattern.ReplaceAllString(save.FileName, "")
+	return strings.TrimSpace(strings.TrimSuffix(name, filepath.Ext(name))) + filepath.Ext(name)
+}
@@ -0,0 +1,40 @@
+package sync
+
+import (
+	"grout/internal"
+	"testing"
+)
+
+func TestParseDeviceTag(t *testing.T) {
+	tests := []struct {
+		fileName string
+		wantName string
+		wantID   string
+	}{
+		{"Pokemon Emerald [2025-01-02 15-04-05-000] [Brick#3f9a21c0].srm", "Brick", "3f9a21c0"},
+		{"Pokemon Emerald [2025-01-02 15-04-05-000] [RG35XX Plus#0011aabb].sav", "RG35XX Plus", "0011aabb"},
+		{"Pokemon Emerald [2025-01-02 15-04-05-000].srm", "", ""},
+		{"Pokemon Emerald.srm", "", ""},
+		{"Game [Tag#nothex00].srm", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			name, id := ParseDeviceTag(tt.fileName)
+			if name != tt.wantName || id != tt.wantID {
+				t.Errorf("ParseDeviceTag(%q) = (%q, %q), want (%q, %q)", tt.fileName, name, id, tt.wantName, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestDeviceTagRoundTrip(t *testing.T) {
+	config := &internal.Config{DeviceID: "3f9a21c0", DeviceName: "Kid's [Brick] #2"}
+
+	tag := deviceTag(config)
+	name, id := ParseDeviceTag("Game [2025-01-02 15-04-05-000] " + tag + ".srm")
+
+	if name != "Kid's Brick 2" || id != config.DeviceID {
+		t.Errorf("round trip of %q = (%q, %q)", tag, name, id)
+	}
+}
@@ -0,0 +1,464 @@
+package sync
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"grout/cfw"
+	"grout/internal/fileutil"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// FolderSave is a save made of whole directories, such as PPSSPP's SAVEDATA/<GAMEID>*.
+// A game can own several directories (one per save slot plus shared data), so they are
+// synced together as a single archive.
+type FolderSave struct {
+	EmulatorDir string
+	Root        string
+	GameID      string
+	Dirs        []string
+}
+
+// folderSaveEpoch is stamped on every archive entry so packing the same files always
+// produces the same bytes, and therefore the same content hash.
+var folderSaveEpoch = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// gameIDPattern matches PSP disc IDs and Vita title IDs, e.g. ULUS10041 or ULUS-10041.
+var gameIDPattern = regexp.MustCompile(`([A-Z]{4})[-_]?(\d{5})`)
+
+// pspDiscIDOffset is where PSP UMD images store the disc ID inside the primary volume descriptor.
+const pspDiscIDOffset = 0x8373
+
+func folderGameID(dirName string) string {
+	m := gameIDPattern.FindStringSubmatchIndex(dirName)
+	if m == nil || m[0] != 0 {
+		return ""
+	}
+	return dirName[m[2]:m[3]] + dirName[m[4]:m[5]]
+}
+
+// romGameID reads the game ID a folder-save emulator will use for a ROM, falling back
+// to an ID embedded in the file name.
+func romGameID(scheme, romPath string) string {
+	if scheme == "psp" {
+		if id := readPSPDiscID(romPath); id != "" {
+			return id
+		}
+	}
+
+	if m := gameIDPattern.FindStringSubmatch(filepath.Base(romPath)); m != nil {
+		return m[1] + m[2]
+	}
+
+	return ""
+}
+
+func readPSPDiscID(romPath string) string {
+	f, err := os.Open(romPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	buf := make([]byte, 10)
+	switch strings.ToLower(filepath.Ext(romPath)) {
+	case ".iso":
+		if _, err := f.ReadAt(buf, pspDiscIDOffset); err != nil {
+			return ""
+		}
+	case ".cso":
+		if buf, err = readCSO(f, pspDiscIDOffset, len(buf)); err != nil {
+			return ""
+		}
+	default:
+		return ""
+	}
+
+	return folderGameID(string(buf))
+}
+
+// readCSO reads length bytes at offset from a CISO image without inflating the rest of it.
+// The read must not cross a block boundary, which holds for the small fields we need.
+func readCSO(f *os.File, offset int64, length int) ([]byte, error) {
+	header := make([]byte, 24)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+	if string(header[:4]) != "CISO" {
+		return nil, fmt.Errorf("not a CSO image")
+	}
+
+	blockSize := int64(binary.LittleEndian.Uint32(header[16:20]))
+	align := uint(header[21])
+	if blockSize == 0 {
+		return nil, fmt.Errorf("invalid CSO block size")
+	}
+
+	block := offset / blockSize
+	index := make([]byte, 8)
+	if _, err := f.ReadAt(index, 24+block*4); err != nil {
+		return nil, err
+	}
+
+	start := binary.LittleEndian.Uint32(index[0:4])
+	end := binary.LittleEndian.Uint32(index[4:8])
+	uncompressed := start&0x80000000 != 0
+	pos := int64(start&0x7fffffff) << align
+	size := (int64(end&0x7fffffff) << align) - pos
+
+	raw := make([]byte, size)
+	if _, err := f.ReadAt(raw, pos); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	data := raw
+	if !uncompressed {
+		inflated, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(raw)), blockSize))
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		data = inflated
+	}
+
+	within := offset % blockSize
+	if within+int64(length) > int64(len(data)) {
+		return nil, fmt.Errorf("short CSO block")
+	}
+
+	return data[within : within+int64(length)], nil
+}
+
+// existingFolderSaveRoot returns the first configured root that exists in emulatorDir.
+func existingFolderSaveRoot(layout *cfw.FolderSaveLayout, emulatorDir string) (string, bool) {
+	for _, root := range layout.Roots {
+		candidate := filepath.Join(emulatorDir, filepath.FromSlash(root))
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// findFolderSaves returns the folder saves for a platform, keyed by game ID.
+func findFolderSaves(fsSlug string, layout *cfw.FolderSaveLayout) map[string]*LocalSave {
+	logger := gaba.GetLogger()
+	saves := make(map[string]*LocalSave)
+	basePath := cfw.BaseSavePath()
+
+	for _, folder := range cfw.EmulatorFoldersForFSSlug(fsSlug) {
+		emulatorDir := filepath.Join(basePath, folder)
+		root, ok := existingFolderSaveRoot(layout, emulatorDir)
+		if !ok {
+			continue
+		}
+
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			logger.Error("Failed to read folder save root", "path", root, "error", err)
+			continue
+		}
+
+		dirsByID := make(map[string][]string)
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			if id := folderGameID(entry.Name()); id != "" {
+				dirsByID[id] = append(dirsByID[id], entry.Name())
+			}
+		}
+
+		for id, dirs := range dirsByID {
+			// Earlier emulator folders take precedence, as with file saves
+			if _, exists := saves[id]; exists {
+				continue
+			}
+
+			save, err := loadFolderSave(fsSlug, &FolderSave{EmulatorDir: emulatorDir, Root: root, GameID: id, Dirs: dirs})
+			if err != nil {
+				logger.Warn("Failed to read folder save", "root", root, "gameID", id, "error", err)
+				continue
+			}
+			saves[id] = save
+		}
+
+		logger.Debug("Found folder saves", "path", root, "count", len(dirsByID))
+	}
+
+	return saves
+}
+
+// loadFolderSave builds a LocalSave for a folder save. Its Path does not exist on disk;
+// it is a stable key for sync state, the retry queue and the sync plan.
+func loadFolderSave(fsSlug string, folder *FolderSave) (*LocalSave, error) {
+	sort.Strings(folder.Dirs)
+
+	save := &LocalSave{
+		FSSlug: fsSlug,
+		Path:   filepath.Join(folder.Root, folder.GameID),
+		Folder: folder,
+	}
+
+	for _, dir := range folder.Dirs {
+		err := filepath.WalkDir(filepath.Join(folder.Root, dir), func(_ string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			save.Size += info.Size()
+			if info.ModTime().After(save.LastModified) {
+				save.LastModified = info.ModTime()
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return save, nil
+}
+
+// reloadFolderSave finds the directories of a game's folder save under root again and
+// builds its LocalSave. It fails with fs.ErrNotExist when none are left.
+func reloadFolderSave(fsSlug, emulatorDir, root, gameID string) (*LocalSave, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := &FolderSave{EmulatorDir: emulatorDir, Root: root, GameID: gameID}
+	for _, entry := range entries {
+		if entry.IsDir() && folderGameID(entry.Name()) == gameID {
+			folder.Dirs = append(folder.Dirs, entry.Name())
+		}
+	}
+	if len(folder.Dirs) == 0 {
+		return nil, fs.ErrNotExist
+	}
+
+	return loadFolderSave(fsSlug, folder)
+}
+
+// attachFolderSaves matches folder saves to ROMs through the game ID read from each ROM.
+func attachFolderSaves(fsSlug string, roms []LocalRomFile) {
+	layout := cfw.SaveLayoutForFSSlug(fsSlug).FolderSaves
+	if layout == nil {
+		return
+	}
+
+	saves := findFolderSaves(fsSlug, layout)
+	if len(saves) == 0 {
+		return
+	}
+
+	for i := range roms {
+		if roms[i].SaveFile != nil {
+			continue
+		}
+		if save, ok := saves[romGameID(layout.GameID, roms[i].Path)]; ok {
+			roms[i].SaveFile = save
+		}
+	}
+}
+
+// pack archives the save directories with sorted entries, fixed modes and fixed times.
+func (f *FolderSave) pack() ([]byte, error) {
+	var entries []string
+	for _, dir := range f.Dirs {
+		err := filepath.WalkDir(filepath.Join(f.Root, dir), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(f.Root, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if d.IsDir() {
+				rel += "/"
+			}
+			entries = append(entries, rel)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(entries)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for _, name := range entries {
+		header := &zip.FileHeader{Name: name, Modified: folderSaveEpoch}
+
+		if strings.HasSuffix(name, "/") {
+			header.Method = zip.Store
+			header.SetMode(os.ModeDir | 0755)
+			if _, err := zw.CreateHeader(header); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		header.Method = zip.Deflate
+		header.SetMode(0644)
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := os.ReadFile(filepath.Join(f.Root, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// unpackFolderSave extracts an archived folder save into root and returns its game ID.
+// Everything is extracted to a staging directory first, then the game directories are
+// swapped in with renames. If any swap fails the ones already made are undone, so a failed
+// download never leaves a half-written or mixed save.
+func unpackFolderSave(data []byte, root string, modTime time.Time) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("invalid folder save archive: %w", err)
+	}
+
+	staging, err := os.MkdirTemp(root, ".grout-unpack-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(staging)
+
+	topDirs := make(map[string]bool)
+	for _, f := range zr.File {
+		name := path.Clean(strings.TrimSuffix(f.Name, "/"))
+		if !filepath.IsLocal(name) || !strings.Contains(f.Name, "/") {
+			return "", fmt.Errorf("unexpected entry in folder save archive: %s", f.Name)
+		}
+		topDirs[strings.SplitN(name, "/", 2)[0]] = true
+
+		dest := filepath.Join(staging, filepath.FromSlash(name))
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(dest, 0755); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		if err := extractFolderSaveFile(f, dest, modTime); err != nil {
+			return "", err
+		}
+	}
+
+	gameID := ""
+	dirs := make([]string, 0, len(topDirs))
+	for dir := range topDirs {
+		id := folderGameID(dir)
+		if id == "" || (gameID != "" && id != gameID) {
+			return "", fmt.Errorf("folder save archive has unexpected directory: %s", dir)
+		}
+		gameID = id
+		dirs = append(dirs, dir)
+	}
+	if gameID == "" {
+		return "", fmt.Errorf("folder save archive is empty")
+	}
+	sort.Strings(dirs)
+
+	replaced := filepath.Join(staging, ".replaced")
+	if err := os.Mkdir(replaced, 0755); err != nil {
+		return "", err
+	}
+
+	// Move every existing directory aside before swapping any in, so a failure part way
+	// through can put the whole save back as it was
+	var movedAside, swappedIn []string
+	rollback := func() {
+		for _, dir := range swappedIn {
+			_ = os.RemoveAll(filepath.Join(root, dir))
+		}
+		for _, dir := range movedAside {
+			_ = os.Rename(filepath.Join(replaced, dir), filepath.Join(root, dir))
+		}
+	}
+
+	for _, dir := range dirs {
+		dest := filepath.Join(root, dir)
+		if !fileutil.FileExists(dest) {
+			continue
+		}
+		if err := os.Rename(dest, filepath.Join(replaced, dir)); err != nil {
+			rollback()
+			return "", err
+		}
+		movedAside = append(movedAside, dir)
+	}
+
+	for _, dir := range dirs {
+		if err := os.Rename(filepath.Join(staging, dir), filepath.Join(root, dir)); err != nil {
+			rollback()
+			return "", err
+		}
+		swappedIn = append(swappedIn, dir)
+	}
+
+	return gameID, nil
+}
+
+func extractFolderSaveFile(f *zip.File, dest string, modTime time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Chtimes(dest, modTime, modTime)
+}
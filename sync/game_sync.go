@@ -0,0 +1,179 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"grout/internal"
+	"grout/romm"
+	"path/filepath"
+	"slices"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// forRom narrows a scan down to the single ROM at romPath. Launching one disc of a
+// multi-disc game matches the game's playlist.
+func (s LocalRomScan) forRom(romPath string) LocalRomScan {
+	target := cleanRomPath(romPath)
+	scoped := make(LocalRomScan)
+
+	for fsSlug, roms := range s {
+		for _, rom := range roms {
+			if cleanRomPath(rom.Path) == target || slices.ContainsFunc(rom.Discs, func(disc string) bool {
+				return cleanRomPath(disc) == target
+			}) {
+				scoped[fsSlug] = append(scoped[fsSlug], rom)
+			}
+		}
+	}
+
+	return scoped
+}
+
+func cleanRomPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// SyncGame syncs the save of a single ROM, carrying out only the given action.
+// CFW launch scripts use it to pull a newer save before a game starts (Download)
+// and to push the save once the emulator exits (Upload).
+func SyncGame(host romm.Host, config *internal.Config, romPath string, action SyncAction) ([]SyncResult, error) {
+	logger := gaba.GetLogger()
+
+	scan := scanRomsFor(romPath).forRom(romPath)
+	if len(scan) == 0 {
+		return nil, fmt.Errorf("%s is not in a mapped ROM directory", romPath)
+	}
+
+	syncs, _, err := FindSaveSyncsFromScan(host, config, scan)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SyncResult
+	for i := range syncs {
+		s := &syncs[i]
+		if s.Action != action {
+			logger.Debug("SyncGame: Nothing to do", "game", s.GameBase, "planned", s.Action, "reason", s.Reason)
+			continue
+		}
+
+		result := s.Execute(host, config)
+		if !result.Success {
+			logger.Error("SyncGame: Sync failed", "game", s.GameBase, "error", result.Error)
+		} else {
+			logger.Debug("SyncGame: Sync successful", "game", s.GameBase, "action", result.Action)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// gameSaveSyncs works out how the saves of the ROMs at romPaths compare to RomM's.
+func gameSaveSyncs(host romm.Host, config *internal.Config, romPaths []string) ([]SaveSync, error) {
+	full := ScanRoms()
+	scan := make(LocalRomScan)
+	seen := make(map[string]bool)
+	for _, romPath := range romPaths {
+		for fsSlug, roms := range full.forRom(romPath) {
+			for _, rom := range roms {
+				if !seen[rom.Path] {
+					seen[rom.Path] = true
+					scan[fsSlug] = append(scan[fsSlug], rom)
+				}
+			}
+		}
+	}
+
+	if len(scan) == 0 {
+		return nil, nil
+	}
+
+	syncs, _, err := FindSaveSyncsFromScan(host, config, scan)
+	return syncs, err
+}
+
+// UploadGameSaves uploads the saves of the ROMs at romPaths that are newer than what
+// RomM has, and leaves every save on the device. Saves excluded from sync, conflicting
+// memory cards and shared memory cards are skipped. It returns how many were uploaded.
+func UploadGameSaves(host romm.Host, config *internal.Config, romPaths []string) (int, error) {
+	syncs, err := gameSaveSyncs(host, config, romPaths)
+	if err != nil {
+		return 0, err
+	}
+
+	uploaded := 0
+	var errs []error
+	for i := range syncs {
+		s := &syncs[i]
+		if s.Local == nil || s.SharedCard || s.Action != Upload {
+			continue
+		}
+		switch s.Reason {
+		case ReasonExcludedGame, ReasonExcludedPlatform, ReasonCardConflict:
+			continue
+		}
+
+		if result := s.Execute(host, config); !result.Success {
+			errs = append(errs, fmt.Errorf("%s: %s", s.GameBase, result.Error))
+			continue
+		}
+		uploaded++
+	}
+
+	return uploaded, errors.Join(errs...)
+}
+
+// RemoveGameSaves deletes the saves of the ROMs at romPaths, uploading first the ones
+// newer than what RomM has. A save that fails to upload is kept, as are saves of games
+// excluded from sync, conflicting memory cards and shared memory cards other games
+// still use. It returns how many saves were deleted.
+func RemoveGameSaves(host romm.Host, config *internal.Config, romPaths []string) (int, error) {
+	logger := gaba.GetLogger()
+
+	syncs, err := gameSaveSyncs(host, config, romPaths)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	var errs []error
+	for i := range syncs {
+		s := &syncs[i]
+		if s.Local == nil || s.SharedCard {
+			continue
+		}
+
+		switch s.Reason {
+		case ReasonExcludedGame, ReasonExcludedPlatform:
+			logger.Debug("RemoveGameSaves: Keeping save excluded from sync", "game", s.GameBase)
+			continue
+		case ReasonCardConflict:
+			logger.Debug("RemoveGameSaves: Keeping conflicting memory card", "game", s.GameBase)
+			continue
+		}
+
+		// Only a save newer than RomM's is uploaded. When RomM's copy is newer, the local
+		// one is stale and uploading it would hand older progress to every other device
+		if s.Action == Upload {
+			if result := s.Execute(host, config); !result.Success {
+				errs = append(errs, fmt.Errorf("%s: %s", s.GameBase, result.Error))
+				continue
+			}
+		}
+
+		if err := s.Local.remove(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.GameBase, err))
+			continue
+		}
+
+		logger.Debug("RemoveGameSaves: Removed save", "game", s.GameBase, "path", s.Local.Path)
+		removed++
+	}
+
+	return removed, errors.Join(errs...)
+}
@@ -0,0 +1,135 @@
+package sync
+
+import (
+	"bufio"
+	"grout/cfw"
+	"grout/internal/fileutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// romEntry is a game found in a ROM directory. Multi-disc games are a single entry for
+// the .m3u playlist, with the discs it lists in order.
+type romEntry struct {
+	path  string
+	discs []string
+}
+
+// listRomEntries lists the games in romDir. Disc images referenced by an .m3u are folded
+// into that playlist's entry, so a multi-disc game is matched and synced only once.
+// Game folders holding a playlist of the same name, as NextUI uses, count as one game too.
+func listRomEntries(romDir string, entries []os.DirEntry) []romEntry {
+	var games []romEntry
+	claimed := make(map[string]bool)
+
+	for _, entry := range fileutil.FilterVisibleFiles(entries) {
+		if !strings.EqualFold(filepath.Ext(entry.Name()), ".m3u") {
+			continue
+		}
+		path := filepath.Join(romDir, entry.Name())
+		discs := readM3U(path)
+		for _, disc := range discs {
+			claimed[disc] = true
+		}
+		games = append(games, romEntry{path: path, discs: discs})
+	}
+
+	for _, entry := range fileutil.FilterHiddenDirectories(entries) {
+		path := filepath.Join(romDir, entry.Name(), entry.Name()+".m3u")
+		if fileutil.FileExists(path) {
+			games = append(games, romEntry{path: path, discs: readM3U(path)})
+		}
+	}
+
+	for _, entry := range fileutil.FilterVisibleFiles(entries) {
+		path := filepath.Join(romDir, entry.Name())
+		if strings.EqualFold(filepath.Ext(entry.Name()), ".m3u") || claimed[path] {
+			continue
+		}
+		games = append(games, romEntry{path: path})
+	}
+
+	return games
+}
+
+// readM3U returns the disc paths listed in a playlist, resolved against its directory.
+func readM3U(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var discs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = filepath.FromSlash(strings.ReplaceAll(line, "\\", "/"))
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(filepath.Dir(path), line)
+		}
+		discs = append(discs, filepath.Clean(line))
+	}
+
+	return discs
+}
+
+// saveBases lists the names a multi-disc game's save may have: the playlist first,
+// then each disc, since some emulators name the save after the disc they booted.
+func (lrf LocalRomFile) saveBases() []string {
+	bases := []string{strings.TrimSuffix(lrf.FileName, filepath.Ext(lrf.FileName))}
+	for _, disc := range lrf.Discs {
+		name := filepath.Base(disc)
+		bases = append(bases, strings.TrimSuffix(name, filepath.Ext(name)))
+	}
+	return bases
+}
+
+// firstDiscBase is the save name used by emulators listed in first_disc_saves.
+func (lrf LocalRomFile) firstDiscBase() string {
+	if len(lrf.Discs) == 0 {
+		return ""
+	}
+	name := filepath.Base(lrf.Discs[0])
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// matchDiscSave picks the one save to sync for a multi-disc game. When saves exist under
+// several names, the one named the way its emulator expects wins, then the newest.
+func matchDiscSave(rom LocalRomFile, saveFileMap map[string]*LocalSave) *LocalSave {
+	var found []*LocalSave
+	for _, base := range rom.saveBases() {
+		if save, ok := saveFileMap[base]; ok {
+			found = append(found, save)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil
+	case 1:
+		return found[0]
+	}
+
+	playlistBase := rom.saveBases()[0]
+	var newest *LocalSave
+	for _, save := range found {
+		emulator := filepath.Base(filepath.Dir(save.Path))
+		expected := playlistBase
+		if cfw.NamesSavesAfterFirstDisc(rom.FSSlug, emulator) {
+			expected = rom.firstDiscBase()
+		}
+		if strings.TrimSuffix(filepath.Base(save.Path), filepath.Ext(save.Path)) == expected {
+			return save
+		}
+		if newest == nil || save.LastModified.After(newest.LastModified) {
+			newest = save
+		}
+	}
+
+	return newest
+}
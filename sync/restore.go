@@ -0,0 +1,259 @@
+package sync
+
+import (
+	"fmt"
+	"grout/cache"
+	"grout/cfw"
+	"grout/internal"
+	"grout/romm"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// RestoreItem is a remote save that can be written to this device, regardless of
+// whether the ROM it belongs to is currently on the card.
+type RestoreItem struct {
+	Sync       SaveSync
+	Rom        romm.Rom
+	Platform   romm.Platform
+	DestPath   string
+	RomPresent bool
+}
+
+// FindRestorableSaves lists the newest remote save of every ROM on every mapped platform
+// that has no save on this device yet, along with shared memory cards this device doesn't
+// have. Games and platforms excluded from sync are left out. Nothing is written to disk.
+func FindRestorableSaves(host romm.Host, config *internal.Config) ([]RestoreItem, error) {
+	logger := gaba.GetLogger()
+	if config == nil {
+		return nil, fmt.Errorf("config is nil")
+	}
+	rc := romm.NewClientFromHost(host, config.ApiTimeout)
+
+	platforms, err := getPlatforms(rc)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve platforms: %w", err)
+	}
+
+	platformsBySlug := make(map[string]romm.Platform)
+	fsSlugToPlatformID := make(map[string]int)
+	var fsSlugs []string
+	for _, p := range platforms {
+		if _, mapped := config.DirectoryMappings[p.FSSlug]; !mapped {
+			continue
+		}
+		if config.IsPlatformSyncExcluded(p.FSSlug) {
+			logger.Debug("Restore: Platform excluded from sync", "fsSlug", p.FSSlug)
+			continue
+		}
+		if len(cfw.EmulatorFoldersForFSSlug(p.FSSlug)) == 0 {
+			logger.Debug("Restore: No save folders for platform", "fsSlug", p.FSSlug)
+			continue
+		}
+		platformsBySlug[p.FSSlug] = p
+		fsSlugToPlatformID[p.FSSlug] = p.ID
+		fsSlugs = append(fsSlugs, p.FSSlug)
+	}
+
+	savesByPlatform := fetchPlatformSaves(rc, fsSlugs, fsSlugToPlatformID)
+	savesByRomID := groupSavesByRomID(savesByPlatform)
+
+	romIDs := make([]int, 0, len(savesByRomID))
+	for romID := range savesByRomID {
+		romIDs = append(romIDs, romID)
+	}
+
+	romsByID := make(map[int]romm.Rom)
+	if cm := cache.GetCacheManager(); cm != nil && len(romIDs) > 0 {
+		if roms, err := cm.GetGamesByIDs(romIDs); err == nil {
+			for _, r := range roms {
+				romsByID[r.ID] = r
+			}
+		}
+	}
+
+	// Local saves are looked up once per platform, under every name a sync would match
+	fileSaves := make(map[string]map[string]*LocalSave)
+	folderSaves := make(map[string]map[string]*LocalSave)
+
+	var items []RestoreItem
+	for _, romID := range romIDs {
+		rom, ok := romsByID[romID]
+		if !ok {
+			rom, err = rc.GetRom(romID)
+			if err != nil {
+				logger.Warn("Restore: Could not look up ROM for save", "romID", romID, "error", err)
+				continue
+			}
+		}
+
+		fsSlug := rom.PlatformFSSlug
+		platform, ok := platformsBySlug[fsSlug]
+		if !ok {
+			continue
+		}
+		if _, excluded := syncExclusion(config, fsSlug, rom.ID); excluded {
+			continue
+		}
+
+		remote := LocalRomFile{RemoteSaves: savesByRomID[romID]}.lastRemoteSave()
+		gameBase := restoreGameBase(rom, config)
+		discs := restoreDiscs(rom, config)
+
+		saveDir, err := saveFolderPath(fsSlug, rom.ID, config)
+		if err != nil {
+			logger.Debug("Restore: No save folder for ROM", "rom", rom.Name, "error", err)
+			continue
+		}
+
+		var destPath string
+		exists := false
+		if layout := cfw.SaveLayoutForFSSlug(fsSlug).FolderSaves; layout != nil && normalizeExt(remote.FileExtension) == ".zip" {
+			romPath := rom.GetLocalPath(*config)
+			if romPath == "" {
+				romPath = rom.FsName
+			}
+			gameID := romGameID(layout.GameID, romPath)
+			if gameID == "" {
+				// Without the game ID there is no telling whether the save is already here
+				logger.Debug("Restore: No game ID for folder save", "rom", rom.Name)
+				continue
+			}
+
+			if _, loaded := folderSaves[fsSlug]; !loaded {
+				folderSaves[fsSlug] = findFolderSaves(fsSlug, layout)
+			}
+			_, exists = folderSaves[fsSlug][gameID]
+
+			root, found := existingFolderSaveRoot(layout, saveDir)
+			if !found {
+				root = filepath.Join(saveDir, filepath.FromSlash(layout.Roots[0]))
+			}
+			destPath = filepath.Join(root, gameID)
+		} else {
+			if _, loaded := fileSaves[fsSlug]; !loaded {
+				fileSaves[fsSlug] = buildSaveFileMap(fsSlug)
+			}
+			// A multi-disc game's save may be named after the playlist or any of its discs
+			bases := []string{gameBase}
+			for _, disc := range discs {
+				bases = append(bases, strings.TrimSuffix(filepath.Base(disc), filepath.Ext(disc)))
+			}
+			for _, base := range bases {
+				if _, found := fileSaves[fsSlug][base]; found {
+					exists = true
+				}
+			}
+
+			name := gameBase
+			if discBase := (LocalRomFile{Discs: discs}).firstDiscBase(); discBase != "" && cfw.NamesSavesAfterFirstDisc(fsSlug, filepath.Base(saveDir)) {
+				name = discBase
+			}
+			destPath = filepath.Join(saveDir, name+normalizeExt(remote.FileExtension))
+		}
+
+		if exists {
+			// Saves already on the device are left to the regular sync
+			continue
+		}
+
+		items = append(items, RestoreItem{
+			Sync: SaveSync{
+				RomID:    rom.ID,
+				RomName:  rom.Name,
+				FSSlug:   fsSlug,
+				GameBase: gameBase,
+				DiscBase: LocalRomFile{Discs: discs}.firstDiscBase(),
+				Remote:   remote,
+				Action:   Download,
+			},
+			Rom:        rom,
+			Platform:   platform,
+			DestPath:   destPath,
+			RomPresent: rom.IsDownloaded(*config),
+		})
+	}
+
+	items = append(items, findRestorableCards(config, platformsBySlug, savesByPlatform)...)
+
+	slices.SortFunc(items, func(a, b RestoreItem) int {
+		if c := strings.Compare(a.Platform.Name, b.Platform.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Rom.Name, b.Rom.Name)
+	})
+
+	logger.Debug("Restore: Found restorable saves", "count", len(items))
+	return items, nil
+}
+
+// findRestorableCards lists the shared memory cards on RomM that this device doesn't have.
+// A card needs no ROM, so it is always restorable.
+func findRestorableCards(config *internal.Config, platformsBySlug map[string]romm.Platform, savesByPlatform map[string][]romm.Save) []RestoreItem {
+	var items []RestoreItem
+
+	for fsSlug, saves := range savesByPlatform {
+		platform, ok := platformsBySlug[fsSlug]
+		if !ok || len(cfw.SaveLayoutForFSSlug(fsSlug).SharedCards) == 0 {
+			continue
+		}
+
+		localCards := findLocalCards(fsSlug)
+		for key, cards := range groupRemoteCards(fsSlug, saves) {
+			remote := newestSave(cards)
+			fileName := key[strings.Index(key, "/")+1:]
+
+			// Cards from an emulator this CFW doesn't have go to the platform's save folder
+			folder := remote.Emulator
+			if !slices.Contains(cfw.EmulatorFoldersForFSSlug(fsSlug), folder) {
+				saveDir, err := saveFolderPath(fsSlug, 0, config)
+				if err != nil {
+					continue
+				}
+				folder = filepath.Base(saveDir)
+			}
+			if _, exists := localCards[sharedCardKey(folder, fileName)]; exists {
+				continue
+			}
+
+			items = append(items, RestoreItem{
+				Sync: SaveSync{
+					RomID:      remote.RomID,
+					RomName:    fileName,
+					FSSlug:     fsSlug,
+					GameBase:   strings.TrimSuffix(fileName, filepath.Ext(fileName)),
+					Remote:     remote,
+					Action:     Download,
+					SharedCard: true,
+				},
+				Rom:        romm.Rom{ID: remote.RomID, Name: fileName, PlatformFSSlug: fsSlug},
+				Platform:   platform,
+				DestPath:   filepath.Join(cfw.BaseSavePath(), folder, fileName),
+				RomPresent: true,
+			})
+		}
+	}
+
+	return items
+}
+
+// restoreGameBase is the save name the emulator will look for once the ROM is on the card.
+func restoreGameBase(rom romm.Rom, config *internal.Config) string {
+	if localPath := rom.GetLocalPath(*config); localPath != "" {
+		name := filepath.Base(localPath)
+		return strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return rom.FsNameNoExt
+}
+
+// restoreDiscs lists the discs of a multi-disc game already on the card, from its playlist.
+func restoreDiscs(rom romm.Rom, config *internal.Config) []string {
+	localPath := rom.GetLocalPath(*config)
+	if !strings.EqualFold(filepath.Ext(localPath), ".m3u") {
+		return nil
+	}
+	return readM3U(localPath)
+}
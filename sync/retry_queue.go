@@ -0,0 +1,304 @@
+package sync
+
+import (
+	"fmt"
+	"grout/cache"
+	"grout/cfw"
+	"grout/internal"
+	"grout/romm"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+const (
+	retryBaseDelay = time.Minute
+	retryMaxDelay  = 6 * time.Hour
+)
+
+// queueKey identifies a sync in the retry queue. Downloads include the game base, since
+// shared memory cards are uploaded against another game's ROM ID.
+func (s SaveSync) queueKey() string {
+	if s.Local != nil {
+		return s.Local.Path
+	}
+	return fmt.Sprintf("download_%d_%s", s.RomID, s.GameBase)
+}
+
+// retryDelay doubles the wait after every failed attempt, capped at retryMaxDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMaxDelay)
+}
+
+// queueFailedSync records a failed sync so it is retried later, even after a restart.
+func queueFailedSync(s SaveSync, syncErr string) {
+	if s.Action != Upload && s.Action != Download {
+		return
+	}
+
+	cm := cache.GetCacheManager()
+	key := s.queueKey()
+
+	entry, found := cm.GetPendingSync(key)
+	if !found {
+		entry = cache.PendingSync{Key: key}
+	}
+
+	entry.Action = string(s.Action)
+	entry.RomID = s.RomID
+	entry.RomName = s.RomName
+	entry.FSSlug = s.FSSlug
+	entry.GameBase = s.GameBase
+	entry.Remote = s.Remote
+	entry.SavePath = ""
+	entry.FolderEmulatorDir, entry.FolderRoot, entry.FolderGameID = "", "", ""
+	if s.Local != nil {
+		entry.SavePath = s.Local.Path
+		if f := s.Local.Folder; f != nil {
+			entry.FolderEmulatorDir, entry.FolderRoot, entry.FolderGameID = f.EmulatorDir, f.Root, f.GameID
+		}
+	}
+	entry.Attempts++
+	entry.LastError = syncErr
+	entry.NextAttemptAt = time.Now().Add(retryDelay(entry.Attempts))
+
+	if err := cm.SavePendingSync(entry); err != nil {
+		gaba.GetLogger().Error("Failed to queue sync for retry", "game", s.GameBase, "error", err)
+		return
+	}
+
+	gaba.GetLogger().Info("Queued sync for retry",
+		"game", s.GameBase,
+		"action", s.Action,
+		"attempts", entry.Attempts,
+		"nextAttempt", entry.NextAttemptAt)
+}
+
+// waitingForRetry reports whether the same sync already failed and is waiting out its
+// backoff, so a scan leaves it to the retry queue instead of counting another attempt.
+func waitingForRetry(s SaveSync) bool {
+	entry, found := cache.GetCacheManager().GetPendingSync(s.queueKey())
+	return found && entry.Action == string(s.Action) && entry.NextAttemptAt.After(time.Now())
+}
+
+func dequeueSync(s SaveSync) {
+	_ = cache.GetCacheManager().DeletePendingSync(s.queueKey())
+}
+
+// QueuedSyncs returns every sync waiting to be retried, oldest first.
+func QueuedSyncs() []cache.PendingSync {
+	pending, err := cache.GetCacheManager().GetPendingSyncs()
+	if err != nil {
+		return nil
+	}
+	return pending
+}
+
+// HasDueQueuedSyncs reports whether any queued sync has waited out its backoff.
+func HasDueQueuedSyncs() bool {
+	now := time.Now()
+	for _, p := range QueuedSyncs() {
+		if !p.NextAttemptAt.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryQueuedSyncs executes every queued sync whose backoff has expired. Entries that
+// are no longer needed (the save was deleted, or it matches what was last synced) are dropped.
+// Uploads are checked against the saves now on RomM first, so a save uploaded from another
+// device while this one was offline is never overwritten.
+func RetryQueuedSyncs(host romm.Host, config *internal.Config) []SyncResult {
+	logger := gaba.GetLogger()
+	now := time.Now()
+	rc := romm.NewClientFromHost(host, config.ApiTimeout)
+
+	var results []SyncResult
+	for _, p := range QueuedSyncs() {
+		if p.NextAttemptAt.After(now) {
+			continue
+		}
+
+		s, ok := pendingToSaveSync(p)
+		if _, excluded := syncExclusion(config, p.FSSlug, p.RomID); excluded {
+			ok = false
+		}
+		if !ok {
+			logger.Debug("Dropping queued sync that is no longer needed", "game", p.GameBase, "action", p.Action)
+			_ = cache.GetCacheManager().DeletePendingSync(p.Key)
+			continue
+		}
+
+		if s.Action == Upload {
+			remoteSaves, err := rc.GetSaves(romm.SaveQuery{RomID: s.RomID})
+			if err != nil {
+				logger.Debug("Leaving queued upload until RomM can be reached", "game", s.GameBase, "error", err)
+				continue
+			}
+			if !replanUpload(&s, remoteSaves) {
+				logger.Debug("Dropping queued upload, the next scan decides on it", "game", s.GameBase)
+				_ = cache.GetCacheManager().DeletePendingSync(p.Key)
+				continue
+			}
+		}
+
+		logger.Debug("Retrying queued sync", "game", s.GameBase, "action", s.Action, "attempt", p.Attempts+1)
+		results = append(results, s.Execute(host, config))
+	}
+
+	return results
+}
+
+func pendingToSaveSync(p cache.PendingSync) (SaveSync, bool) {
+	s := SaveSync{
+		RomID:    p.RomID,
+		RomName:  p.RomName,
+		FSSlug:   p.FSSlug,
+		GameBase: p.GameBase,
+		Remote:   p.Remote,
+		Action:   SyncAction(p.Action),
+	}
+	// Shared cards are queued under their own file name rather than a game's
+	s.SharedCard = cfw.IsSharedCard(p.FSSlug, p.RomName)
+
+	switch {
+	case p.FolderRoot != "":
+		local, err := reloadFolderSave(p.FSSlug, p.FolderEmulatorDir, p.FolderRoot, p.FolderGameID)
+		if err != nil {
+			gaba.GetLogger().Debug("Queued folder save is gone", "game", p.GameBase, "root", p.FolderRoot, "error", err)
+			return s, false
+		}
+		s.Local = local
+	case p.SavePath != "":
+		info, err := os.Stat(p.SavePath)
+		if err != nil {
+			gaba.GetLogger().Debug("Queued save is gone", "game", p.GameBase, "path", p.SavePath, "error", err)
+			return s, false
+		}
+		s.Local = &LocalSave{
+			FSSlug:       p.FSSlug,
+			Path:         p.SavePath,
+			LastModified: info.ModTime(),
+			Size:         info.Size(),
+		}
+	}
+
+	switch s.Action {
+	case Upload:
+		if s.Local == nil {
+			return s, false
+		}
+		// Don't push a save that has since been synced by other means
+		if state, found := cache.GetCacheManager().GetSaveSyncState(s.Local.Path); found {
+			if hash, err := s.Local.hash(); err == nil && hash == state.ContentHash {
+				return s, false
+			}
+		}
+	case Download:
+		if s.Remote.ID == 0 {
+			return s, false
+		}
+		// A local save edited since the failure is left for the next scan to decide on
+		if s.Local != nil {
+			state, found := cache.GetCacheManager().GetSaveSyncState(s.Local.Path)
+			if hash, err := s.Local.hash(); !found || err != nil || hash != state.ContentHash {
+				return s, false
+			}
+		}
+	default:
+		return s, false
+	}
+
+	return s, true
+}
+
+// replanUpload decides on a queued upload again, the same way a scan would, against the
+// ROM's current remote saves. It reports whether the save should still be uploaded.
+func replanUpload(s *SaveSync, remoteSaves []romm.Save) bool {
+	var action SyncAction
+	if s.SharedCard {
+		emulator := filepath.Base(filepath.Dir(s.Local.Path))
+		var cards []romm.Save
+		for _, save := range remoteSaves {
+			if sharedCardKey(save.Emulator, untaggedSaveName(save)) == sharedCardKey(emulator, s.RomName) {
+				cards = append(cards, save)
+			}
+		}
+		s.Remote = newestSave(cards)
+		action, s.Reason = sharedCardSyncAction(s.Local, s.Remote)
+	} else {
+		var saves []romm.Save
+		for _, save := range remoteSaves {
+			if !cfw.IsSharedCard(s.FSSlug, untaggedSaveName(save)) {
+				saves = append(saves, save)
+			}
+		}
+		rom := LocalRomFile{RomID: s.RomID, FSSlug: s.FSSlug, SaveFile: s.Local, RemoteSaves: saves}
+		action, s.Reason = rom.syncAction()
+		s.Remote = rom.lastRemoteSave()
+	}
+	return action == Upload
+}
+
+// queueChangedSaves queues an upload for every save that changed since its last sync,
+// and for saves that were never synced but belong to a game known from the cache. It
+// needs no server access, so edits made while offline are not forgotten.
+func queueChangedSaves(scan LocalRomScan, reason string) int {
+	cm := cache.GetCacheManager()
+	queued := 0
+
+	for _, roms := range scan {
+		for _, rom := range roms {
+			if rom.SaveFile == nil {
+				continue
+			}
+
+			baseName := strings.TrimSuffix(rom.FileName, filepath.Ext(rom.FileName))
+			// Saves named after a disc are uploaded under their own name, as a scan would
+			if rom.SaveFile.Folder == nil {
+				baseName = strings.TrimSuffix(filepath.Base(rom.SaveFile.Path), filepath.Ext(rom.SaveFile.Path))
+			}
+
+			s := SaveSync{
+				FSSlug:   rom.FSSlug,
+				GameBase: baseName,
+				Local:    rom.SaveFile,
+				Action:   Upload,
+			}
+
+			state, found := cm.GetSaveSyncState(rom.SaveFile.Path)
+			if found && state.RomID != 0 {
+				hash, err := rom.SaveFile.hash()
+				if err != nil || hash == state.ContentHash {
+					continue
+				}
+				s.RomID = state.RomID
+				s.Reason = ReasonLocalChanged
+			} else {
+				romID, romName, ok := cm.GetRomIDByFilename(rom.FSSlug, rom.FileName)
+				if !ok {
+					continue
+				}
+				s.RomID, s.RomName = romID, romName
+				s.Reason = ReasonOnlyLocal
+			}
+
+			if _, exists := cm.GetPendingSync(s.queueKey()); exists {
+				continue
+			}
+
+			queueFailedSync(s, reason)
+			queued++
+		}
+	}
+
+	return queued
+}
@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"grout/cache"
+	"grout/internal/fileutil"
+	"grout/romm"
+	"hash/crc32"
+	"os"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// lookupRomIDByHash matches a local ROM whose filename differs from RomM by its CRC32,
+// only ever to a game on the ROM's own platform.
+// The hash and the resulting match are stored in the cache keyed by size and mtime,
+// so each file is only hashed once and the server is only asked once.
+func lookupRomIDByHash(rc *romm.Client, romFile *LocalRomFile) (int, string) {
+	logger := gaba.GetLogger()
+
+	if romFile.Path == "" {
+		return 0, ""
+	}
+
+	info, err := os.Stat(romFile.Path)
+	if err != nil || info.IsDir() {
+		return 0, ""
+	}
+
+	cm := cache.GetCacheManager()
+
+	entry, found := cm.GetLocalRomHash(romFile.Path, info.Size(), info.ModTime())
+	if found && entry.RomID > 0 {
+		logger.Debug("ROM lookup from remembered hash match", "file", romFile.FileName, "romID", entry.RomID)
+		return entry.RomID, entry.RomName
+	}
+
+	if !found {
+		crc, err := fileutil.HashFile(romFile.Path, crc32.NewIEEE())
+		if err != nil {
+			logger.Warn("Failed to hash ROM", "path", romFile.Path, "error", err)
+			return 0, ""
+		}
+		entry = cache.LocalRomHash{
+			Path:    romFile.Path,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			CRC:     crc,
+		}
+	}
+
+	if romID, romName, ok := cm.GetRomByHash(romFile.FSSlug, "", "", entry.CRC); ok {
+		entry.RomID, entry.RomName = romID, romName
+	} else if !found && rc != nil {
+		// Only ask the server the first time; later scans rely on the refreshed games cache
+		rom, err := rc.GetRomByHash(romm.GetRomByHashQuery{CrcHash: entry.CRC})
+		switch {
+		case err == nil && rom.ID > 0 && rom.PlatformFSSlug == romFile.FSSlug:
+			entry.RomID, entry.RomName = rom.ID, rom.Name
+		case err == nil && rom.ID > 0:
+			// The same dump can be listed under another platform, such as a GB game on GBC
+			logger.Debug("Ignoring hash match on another platform", "file", romFile.FileName, "fsSlug", romFile.FSSlug, "matchFSSlug", rom.PlatformFSSlug)
+		case err != nil:
+			logger.Debug("No server match for ROM hash", "file", romFile.FileName, "crc", entry.CRC, "error", err)
+		}
+	}
+
+	if err := cm.SaveLocalRomHash(entry); err != nil {
+		logger.Debug("Unable to remember ROM hash", "path", romFile.Path, "error", err)
+	}
+
+	if entry.RomID > 0 {
+		logger.Debug("ROM lookup by hash", "file", romFile.FileName, "crc", entry.CRC, "romID", entry.RomID, "name", entry.RomName)
+	}
+
+	return entry.RomID, entry.RomName
+}
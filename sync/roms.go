@@ -1,6 +1,7 @@
 package sync
 
 import (
+	"grout/cache"
 	"grout/cfw"
 	"grout/internal"
 	"grout/internal/fileutil"
@@ -17,28 +18,37 @@ import (
 )
 
 type LocalRomFile struct {
-	RomID       int
-	RomName     string
-	FSSlug      string
-	FileName    string
+	RomID    int
+	RomName  string
+	FSSlug   string
+	FileName string
+	Path     string
+	// Discs are the disc images listed in an .m3u playlist, in order
+	Discs       []string
 	RemoteSaves []romm.Save
 	SaveFile    *LocalSave
 }
 
-func (lrf LocalRomFile) syncAction() SyncAction {
+func (lrf LocalRomFile) syncAction() (SyncAction, SyncReason) {
 	hasLocal := lrf.SaveFile != nil
 	hasRemote := len(lrf.RemoteSaves) > 0
 
 	switch {
 	case !hasLocal && !hasRemote:
-		return Skip
+		return Skip, ReasonNoSaves
 	case hasLocal && !hasRemote:
-		return Upload
+		return Upload, ReasonOnlyLocal
 	case !hasLocal && hasRemote:
-		return Download
+		return Download, ReasonOnlyRemote
 	}
 
-	// Both local and remote exist - compare timestamps
+	// Both local and remote exist. Device clocks are unreliable, so first check whether
+	// either side actually changed since the last successful sync.
+	if action, reason, ok := lrf.hashSyncAction(); ok {
+		return action, reason
+	}
+
+	// Both sides changed (or this save has never been synced) - compare timestamps
 	// Truncate to second precision to avoid timestamp precision issues
 	// API timestamps are typically second/millisecond precision, but filesystem is nanosecond
 	localTime := lrf.SaveFile.LastModified.Truncate(time.Second)
@@ -46,11 +56,48 @@ func (lrf LocalRomFile) syncAction() SyncAction {
 
 	switch localTime.Compare(remoteTime) {
 	case -1:
-		return Download
+		return Download, ReasonRemoteNewer
 	case 1:
-		return Upload
+		return Upload, ReasonLocalNewer
+	default:
+		return Skip, ReasonSameTimestamp
+	}
+}
+
+// hashSyncAction compares the local save's content hash and the newest remote save
+// against the state recorded at the last sync. It returns false when there is no
+// recorded state or when both sides changed, leaving the decision to the timestamps.
+func (lrf LocalRomFile) hashSyncAction() (SyncAction, SyncReason, bool) {
+	logger := gaba.GetLogger()
+
+	state, found := cache.GetCacheManager().GetSaveSyncState(lrf.SaveFile.Path)
+	if !found {
+		return Skip, "", false
+	}
+
+	localHash, err := lrf.SaveFile.hash()
+	if err != nil {
+		logger.Warn("Failed to hash local save", "path", lrf.SaveFile.Path, "error", err)
+		return Skip, "", false
+	}
+
+	localChanged := localHash != state.ContentHash
+	remoteChanged := lrf.lastRemoteSave().ID != state.RemoteSaveID
+
+	logger.Debug("Compared save against last sync",
+		"path", lrf.SaveFile.Path,
+		"localChanged", localChanged,
+		"remoteChanged", remoteChanged)
+
+	switch {
+	case !localChanged && !remoteChanged:
+		return Skip, ReasonUnchanged, true
+	case !localChanged && remoteChanged:
+		return Download, ReasonRemoteChanged, true
+	case localChanged && !remoteChanged:
+		return Upload, ReasonLocalChanged, true
 	default:
-		return Skip
+		return Skip, "", false
 	}
 }
 
@@ -71,6 +118,18 @@ type LocalRomScan map[string][]LocalRomFile
 
 // ScanRoms scans all local ROM directories and matches with save files
 func ScanRoms() LocalRomScan {
+	return scanRoms("")
+}
+
+// scanRomsFor scans only the platform directory romPath is in, which is all a single
+// game's sync needs.
+func scanRomsFor(romPath string) LocalRomScan {
+	return scanRoms(cleanRomPath(romPath))
+}
+
+// scanRoms scans every platform directory, or when within is set only the ones
+// containing that path.
+func scanRoms(within string) LocalRomScan {
 	logger := gaba.GetLogger()
 	result := make(map[string][]LocalRomFile)
 	currentCFW := cfw.GetCFW()
@@ -86,7 +145,7 @@ func ScanRoms() LocalRomScan {
 
 	config, _ := internal.LoadConfig()
 
-	result = scanRomsByPlatform(baseRomDir, platformMap, config, currentCFW)
+	result = scanRomsByPlatform(baseRomDir, platformMap, config, currentCFW, within)
 
 	totalRoms := 0
 	for _, roms := range result {
@@ -101,16 +160,28 @@ func buildSaveFileMap(fsSlug string) map[string]*LocalSave {
 	saveFiles := findSaveFiles(fsSlug)
 	saveFileMap := make(map[string]*LocalSave)
 	for i := range saveFiles {
+		// Shared memory cards belong to the platform, not to a ROM that happens to share the name
+		if cfw.IsSharedCard(fsSlug, filepath.Base(saveFiles[i].Path)) {
+			continue
+		}
 		baseName := strings.TrimSuffix(filepath.Base(saveFiles[i].Path), filepath.Ext(saveFiles[i].Path))
 		saveFileMap[baseName] = &saveFiles[i]
 	}
 	return saveFileMap
 }
 
-func scanRomsByPlatform(baseRomDir string, platformMap map[string][]string, config *internal.Config, currentCFW cfw.CFW) map[string][]LocalRomFile {
+func scanRomsByPlatform(baseRomDir string, platformMap map[string][]string, config *internal.Config, currentCFW cfw.CFW, within string) map[string][]LocalRomFile {
 	logger := gaba.GetLogger()
 	result := make(map[string][]LocalRomFile)
 
+	skipped := func(romDir string) bool {
+		if within == "" {
+			return false
+		}
+		rel, err := filepath.Rel(cleanRomPath(romDir), within)
+		return err != nil || !filepath.IsLocal(rel)
+	}
+
 	if currentCFW == cfw.NextUI {
 		entries, err := os.ReadDir(baseRomDir)
 		if err != nil {
@@ -152,8 +223,10 @@ func scanRomsByPlatform(baseRomDir string, platformMap map[string][]string, conf
 
 				if matched {
 					romDir := filepath.Join(baseRomDir, dirName)
-					saveFileMap := buildSaveFileMap(fsSlug)
-					roms := scanRomDirectory(fsSlug, romDir, saveFileMap)
+					if skipped(romDir) {
+						continue
+					}
+					roms := scanPlatformRoms(fsSlug, romDir)
 					if len(roms) > 0 {
 						result[fsSlug] = append(result[fsSlug], roms...)
 						logger.Debug("Found ROMs for platform", "fsSlug", fsSlug, "dir", dirName, "count", len(roms))
@@ -195,13 +268,12 @@ func scanRomsByPlatform(baseRomDir string, platformMap map[string][]string, conf
 
 				romDir := filepath.Join(baseRomDir, romFolderName)
 
-				if !fileutil.FileExists(romDir) {
+				if skipped(romDir) || !fileutil.FileExists(romDir) {
 					resultChan <- platformResult{fsSlug: s, roms: nil}
 					return
 				}
 
-				saveFileMap := buildSaveFileMap(s)
-				roms := scanRomDirectory(s, romDir, saveFileMap)
+				roms := scanPlatformRoms(s, romDir)
 				resultChan <- platformResult{fsSlug: s, roms: roms}
 				if len(roms) > 0 {
 					logger.Debug("Found ROMs for platform", "fsSlug", s, "count", len(roms))
@@ -226,6 +298,13 @@ func scanRomsByPlatform(baseRomDir string, platformMap map[string][]string, conf
 	return result
 }
 
+// scanPlatformRoms lists the ROMs in romDir and pairs them with their file or folder saves.
+func scanPlatformRoms(fsSlug, romDir string) []LocalRomFile {
+	roms := scanRomDirectory(fsSlug, romDir, buildSaveFileMap(fsSlug))
+	attachFolderSaves(fsSlug, roms)
+	return roms
+}
+
 func scanRomDirectory(fsSlug, romDir string, saveFileMap map[string]*LocalSave) []LocalRomFile {
 	logger := gaba.GetLogger()
 	var roms []LocalRomFile
@@ -236,18 +315,20 @@ func scanRomDirectory(fsSlug, romDir string, saveFileMap map[string]*LocalSave)
 		return roms
 	}
 
-	visibleFiles := fileutil.FilterVisibleFiles(entries)
-	for _, entry := range visibleFiles {
-		baseName := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
-		var saveFile *LocalSave
-		if sf, found := saveFileMap[baseName]; found {
-			saveFile = sf
-		}
+	for _, game := range listRomEntries(romDir, entries) {
+		fileName := filepath.Base(game.path)
 
 		rom := LocalRomFile{
 			FSSlug:   fsSlug,
-			FileName: entry.Name(),
-			SaveFile: saveFile,
+			FileName: fileName,
+			Path:     game.path,
+			Discs:    game.discs,
+		}
+
+		if len(rom.Discs) > 0 {
+			rom.SaveFile = matchDiscSave(rom, saveFileMap)
+		} else if sf, found := saveFileMap[strings.TrimSuffix(fileName, filepath.Ext(fileName))]; found {
+			rom.SaveFile = sf
 		}
 
 		roms = append(roms, rom)
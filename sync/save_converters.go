@@ -0,0 +1,97 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"grout/cfw"
+	"slices"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// saveConverter transforms save data in one conversion step. It returns the converted
+// data and the file extension the target emulator expects.
+type saveConverter func(data []byte, ext string, step cfw.ConversionStep) ([]byte, string, error)
+
+// saveConverters are the converters that save_layouts.json conversions can refer to.
+// Each one is lossless, so converting back restores the original save.
+var saveConverters = map[string]saveConverter{
+	"rename_ext":    renameExt,
+	"strip_header":  stripHeader,
+	"strip_trailer": stripTrailer,
+	"swap_bytes":    swapBytes,
+}
+
+// convertSave rewrites a save made by fromEmulator so toEmulator can read it. Saves
+// without a matching conversion are returned unchanged.
+func convertSave(fsSlug, fromEmulator, toEmulator string, data []byte, ext string) ([]byte, string, error) {
+	conversion, found := cfw.SaveConversionFor(fsSlug, fromEmulator, toEmulator, ext)
+	if !found {
+		return data, ext, nil
+	}
+
+	for _, step := range conversion.Steps {
+		convert, ok := saveConverters[step.Converter]
+		if !ok {
+			return nil, "", fmt.Errorf("unknown save converter %q", step.Converter)
+		}
+
+		var err error
+		data, ext, err = convert(data, ext, step)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", step.Converter, err)
+		}
+	}
+
+	gaba.GetLogger().Debug("Converted save between emulators",
+		"fsSlug", fsSlug,
+		"from", fromEmulator,
+		"to", toEmulator,
+		"ext", ext)
+
+	return data, ext, nil
+}
+
+func renameExt(data []byte, ext string, step cfw.ConversionStep) ([]byte, string, error) {
+	if step.Ext == "" {
+		return nil, "", fmt.Errorf("no extension given")
+	}
+	return data, normalizeExt(step.Ext), nil
+}
+
+// stripHeader drops a fixed size header, such as the one some standalone emulators
+// write in front of the raw cartridge RAM.
+func stripHeader(data []byte, ext string, step cfw.ConversionStep) ([]byte, string, error) {
+	if step.Size <= 0 || step.Size > len(data) {
+		return nil, "", fmt.Errorf("header size %d does not fit a %d byte save", step.Size, len(data))
+	}
+	return data[step.Size:], ext, nil
+}
+
+// stripTrailer cuts the save at the last occurrence of a marker, like the footer
+// DeSmuME appends to .dsv files. Saves without the marker are left as they are.
+func stripTrailer(data []byte, ext string, step cfw.ConversionStep) ([]byte, string, error) {
+	if step.Marker == "" {
+		return nil, "", fmt.Errorf("no marker given")
+	}
+	if idx := bytes.LastIndex(data, []byte(step.Marker)); idx >= 0 {
+		return data[:idx], ext, nil
+	}
+	return data, ext, nil
+}
+
+// swapBytes reverses the byte order of every Width byte word, converting between
+// big-endian and little-endian dumps of N64 EEPROM, SRAM and FlashRAM.
+func swapBytes(data []byte, ext string, step cfw.ConversionStep) ([]byte, string, error) {
+	if step.Width < 2 || len(data)%step.Width != 0 {
+		return nil, "", fmt.Errorf("cannot swap %d byte words in a %d byte save", step.Width, len(data))
+	}
+
+	swapped := make([]byte, 0, len(data))
+	for word := range slices.Chunk(data, step.Width) {
+		reversed := slices.Clone(word)
+		slices.Reverse(reversed)
+		swapped = append(swapped, reversed...)
+	}
+	return swapped, ext, nil
+}
@@ -0,0 +1,101 @@
+package sync
+
+import (
+	"bytes"
+	"grout/cfw"
+	"testing"
+)
+
+func TestSaveConverters(t *testing.T) {
+	tests := []struct {
+		name      string
+		converter string
+		data      []byte
+		ext       string
+		step      cfw.ConversionStep
+		wantData  []byte
+		wantExt   string
+		wantErr   bool
+	}{
+		{"rename", "rename_ext", []byte{1, 2}, ".sav", cfw.ConversionStep{Ext: ".srm"}, []byte{1, 2}, ".srm", false},
+		{"rename without dot", "rename_ext", []byte{1, 2}, ".sav", cfw.ConversionStep{Ext: "srm"}, []byte{1, 2}, ".srm", false},
+		{"rename without extension", "rename_ext", []byte{1, 2}, ".sav", cfw.ConversionStep{}, nil, "", true},
+
+		{"strip header", "strip_header", []byte{0xAA, 0xBB, 1, 2}, ".sav", cfw.ConversionStep{Size: 2}, []byte{1, 2}, ".sav", false},
+		{"strip whole save as header", "strip_header", []byte{1, 2}, ".sav", cfw.ConversionStep{Size: 2}, []byte{}, ".sav", false},
+		{"header larger than save", "strip_header", []byte{1, 2}, ".sav", cfw.ConversionStep{Size: 3}, nil, "", true},
+		{"no header size", "strip_header", []byte{1, 2}, ".sav", cfw.ConversionStep{}, nil, "", true},
+
+		{"strip trailer", "strip_trailer", []byte("data|<--Snip above here to create a raw sav by excluding this DeSmuME savedata footer:"), ".dsv", cfw.ConversionStep{Marker: "|<--Snip"}, []byte("data"), ".dsv", false},
+		{"trailer missing", "strip_trailer", []byte("data|-DESMUME SAVE-|"), ".dsv", cfw.ConversionStep{Marker: "|<--Snip"}, []byte("data|-DESMUME SAVE-|"), ".dsv", false},
+		{"strip last marker", "strip_trailer", []byte("a|END|b|END|c"), ".dsv", cfw.ConversionStep{Marker: "|END|"}, []byte("a|END|b"), ".dsv", false},
+		{"no marker given", "strip_trailer", []byte("data"), ".dsv", cfw.ConversionStep{}, nil, "", true},
+
+		{"swap 4 byte words", "swap_bytes", []byte{1, 2, 3, 4, 5, 6, 7, 8}, ".sra", cfw.ConversionStep{Width: 4}, []byte{4, 3, 2, 1, 8, 7, 6, 5}, ".sra", false},
+		{"swap 8 byte words", "swap_bytes", []byte{1, 2, 3, 4, 5, 6, 7, 8}, ".eep", cfw.ConversionStep{Width: 8}, []byte{8, 7, 6, 5, 4, 3, 2, 1}, ".eep", false},
+		{"partial word", "swap_bytes", []byte{1, 2, 3}, ".sra", cfw.ConversionStep{Width: 2}, nil, "", true},
+		{"word too narrow", "swap_bytes", []byte{1, 2}, ".sra", cfw.ConversionStep{Width: 1}, nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, ext, err := saveConverters[tt.converter](tt.data, tt.ext, tt.step)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s error = %v, want error %v", tt.converter, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !bytes.Equal(data, tt.wantData) || ext != tt.wantExt {
+				t.Errorf("%s = (%v, %q), want (%v, %q)", tt.converter, data, ext, tt.wantData, tt.wantExt)
+			}
+		})
+	}
+}
+
+func TestSwapBytesRoundTrip(t *testing.T) {
+	original := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	step := cfw.ConversionStep{Width: 4}
+
+	swapped, _, err := swapBytes(original, ".sra", step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, _, err := swapBytes(swapped, ".sra", step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(restored, original) {
+		t.Errorf("swapping twice = %v, want %v", restored, original)
+	}
+}
+
+func TestN64Conversions(t *testing.T) {
+	data := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	tests := []struct {
+		name     string
+		from, to string
+		ext      string
+		want     []byte
+	}{
+		{"EEPROM to standalone", "Mupen64Plus-Next", "Mupen64Plus", ".eep", []byte{8, 7, 6, 5, 4, 3, 2, 1}},
+		{"EEPROM from standalone", "Mupen64Plus", "ParaLLel N64", ".eep", []byte{8, 7, 6, 5, 4, 3, 2, 1}},
+		{"SRAM to standalone", "ParaLLel N64", "N64", ".sra", []byte{4, 3, 2, 1, 8, 7, 6, 5}},
+		{"FlashRAM from standalone", "Mupen64Plus (External - GLideN64)", "Mupen64Plus-Next", ".fla", []byte{4, 3, 2, 1, 8, 7, 6, 5}},
+		{"between cores", "Mupen64Plus-Next", "ParaLLel N64", ".eep", data},
+		{"controller pak", "Mupen64Plus-Next", "Mupen64Plus", ".mpk", data},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ext, err := convertSave("n64", tt.from, tt.to, data, tt.ext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) || ext != tt.ext {
+				t.Errorf("convertSave() = (%v, %q), want (%v, %q)", got, ext, tt.want, tt.ext)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package sync
+
+import (
+	"fmt"
+	"grout/internal"
+	"grout/romm"
+	"slices"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// DefaultSaveRetention is how many saves the manual clean up keeps per game and device
+// when no retention policy is set.
+const DefaultSaveRetention = 5
+
+const deleteBatchSize = 50
+
+// SaveCleanupPlan lists the server saves a clean up would delete.
+type SaveCleanupPlan struct {
+	Saves []romm.Save
+	Bytes int64
+	Keep  int
+}
+
+// saveSlot groups the versions of one save uploaded by one device. Saves for different
+// files on the same ROM, such as a shared memory card, are kept apart.
+func saveSlot(save romm.Save) string {
+	_, deviceID := ParseDeviceTag(save.FileName)
+	return fmt.Sprintf("%d/%s/%s", save.RomID, deviceID, untaggedSaveName(save))
+}
+
+// expiredSaves returns every save older than the newest keep in its slot. Saves without
+// a device tag were not uploaded by Grout, so they are never pruned.
+func expiredSaves(saves []romm.Save, keep int) []romm.Save {
+	if keep <= 0 {
+		return nil
+	}
+
+	slots := make(map[string][]romm.Save)
+	for _, save := range saves {
+		if _, deviceID := ParseDeviceTag(save.FileName); deviceID == "" {
+			continue
+		}
+		slot := saveSlot(save)
+		slots[slot] = append(slots[slot], save)
+	}
+
+	var expired []romm.Save
+	for _, slot := range slots {
+		if len(slot) <= keep {
+			continue
+		}
+		slices.SortFunc(slot, func(a, b romm.Save) int {
+			return b.UpdatedAt.Compare(a.UpdatedAt)
+		})
+		expired = append(expired, slot[keep:]...)
+	}
+
+	return expired
+}
+
+// pruneAfterUpload applies the retention policy to the slot of a save this device just
+// uploaded. Failures are only logged, since the upload itself succeeded.
+func pruneAfterUpload(rc *romm.Client, config *internal.Config, uploaded romm.Save) {
+	if config == nil || config.SaveRetention <= 0 || uploaded.RomID == 0 {
+		return
+	}
+	logger := gaba.GetLogger()
+
+	saves, err := rc.GetSaves(romm.SaveQuery{RomID: uploaded.RomID})
+	if err != nil {
+		logger.Warn("Unable to fetch saves for pruning", "romID", uploaded.RomID, "error", err)
+		return
+	}
+
+	slot := saveSlot(uploaded)
+	saves = slices.DeleteFunc(saves, func(s romm.Save) bool { return saveSlot(s) != slot })
+
+	expired := expiredSaves(saves, config.SaveRetention)
+	if len(expired) == 0 {
+		return
+	}
+
+	if err := deleteSaves(rc, expired, nil); err != nil {
+		logger.Warn("Unable to prune old saves", "romID", uploaded.RomID, "error", err)
+		return
+	}
+
+	logger.Debug("Pruned old saves after upload", "romID", uploaded.RomID, "deleted", len(expired))
+}
+
+// PlanServerSaveCleanup finds the saves on RomM beyond the newest keep per game and device,
+// across every mapped platform.
+func PlanServerSaveCleanup(host romm.Host, config *internal.Config, keep int) (SaveCleanupPlan, error) {
+	plan := SaveCleanupPlan{Keep: keep}
+	if config == nil {
+		return plan, fmt.Errorf("config is nil")
+	}
+	rc := romm.NewClientFromHost(host, config.ApiTimeout)
+
+	platforms, err := getPlatforms(rc)
+	if err != nil {
+		return plan, fmt.Errorf("could not retrieve platforms: %w", err)
+	}
+
+	fsSlugToPlatformID := make(map[string]int)
+	var fsSlugs []string
+	for _, p := range platforms {
+		if _, mapped := config.DirectoryMappings[p.FSSlug]; !mapped {
+			continue
+		}
+		fsSlugToPlatformID[p.FSSlug] = p.ID
+		fsSlugs = append(fsSlugs, p.FSSlug)
+	}
+
+	for _, saves := range fetchPlatformSaves(rc, fsSlugs, fsSlugToPlatformID) {
+		plan.Saves = append(plan.Saves, expiredSaves(saves, keep)...)
+	}
+	for _, save := range plan.Saves {
+		plan.Bytes += int64(save.FileSizeBytes)
+	}
+
+	return plan, nil
+}
+
+// CleanUpServerSaves deletes the saves in a plan and returns how many bytes were freed.
+func CleanUpServerSaves(host romm.Host, config *internal.Config, plan SaveCleanupPlan, progress func(float64)) (int64, error) {
+	if config == nil {
+		return 0, fmt.Errorf("config is nil")
+	}
+	rc := romm.NewClientFromHost(host, config.ApiTimeout)
+
+	var freed int64
+	err := deleteSaves(rc, plan.Saves, func(deleted []romm.Save, done int) {
+		for _, save := range deleted {
+			freed += int64(save.FileSizeBytes)
+		}
+		if progress != nil {
+			progress(float64(done) / float64(len(plan.Saves)))
+		}
+	})
+
+	return freed, err
+}
+
+// deleteSaves deletes saves in batches, calling onBatch after each successful batch.
+func deleteSaves(rc *romm.Client, saves []romm.Save, onBatch func(deleted []romm.Save, done int)) error {
+	done := 0
+	for batch := range slices.Chunk(saves, deleteBatchSize) {
+		ids := make([]int, len(batch))
+		for i, save := range batch {
+			ids[i] = save.ID
+		}
+
+		if err := rc.DeleteSaves(ids); err != nil {
+			return err
+		}
+
+		done += len(batch)
+		if onBatch != nil {
+			onBatch(batch, done)
+		}
+	}
+	return nil
+}
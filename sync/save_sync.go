@@ -1,8 +1,10 @@
 package sync
 
 import (
+	"crypto/sha1"
 	"fmt"
 	"grout/cache"
+	"grout/cfw"
 	"grout/internal"
 	"grout/internal/fileutil"
 	"grout/romm"
@@ -19,9 +21,14 @@ type SaveSync struct {
 	RomName  string
 	FSSlug   string
 	GameBase string
-	Local    *LocalSave
-	Remote   romm.Save
-	Action   SyncAction
+	// DiscBase is the first disc's name, used instead of GameBase when downloading a
+	// multi-disc save for an emulator that names saves after disc 1
+	DiscBase   string
+	Local      *LocalSave
+	Remote     romm.Save
+	Action     SyncAction
+	Reason     SyncReason
+	SharedCard bool
 }
 
 type SyncAction string
@@ -30,15 +37,56 @@ const (
 	Download SyncAction = "DOWNLOAD"
 	Upload   SyncAction = "UPLOAD"
 	Skip     SyncAction = "SKIP"
+	// Conflict keeps the local shared memory card and stores the RomM copy beside it
+	Conflict SyncAction = "CONFLICT"
 )
 
+// SyncReason explains why a sync was planned the way it was.
+type SyncReason string
+
+const (
+	ReasonNoSaves          SyncReason = "no_saves"
+	ReasonOnlyLocal        SyncReason = "only_local"
+	ReasonOnlyRemote       SyncReason = "only_remote"
+	ReasonLocalChanged     SyncReason = "local_changed"
+	ReasonRemoteChanged    SyncReason = "remote_changed"
+	ReasonUnchanged        SyncReason = "unchanged"
+	ReasonLocalNewer       SyncReason = "local_newer"
+	ReasonRemoteNewer      SyncReason = "remote_newer"
+	ReasonSameTimestamp    SyncReason = "same_timestamp"
+	ReasonCardConflict     SyncReason = "card_conflict"
+	ReasonBlankCard        SyncReason = "blank_card"
+	ReasonExcludedGame     SyncReason = "excluded_game"
+	ReasonExcludedPlatform SyncReason = "excluded_platform"
+)
+
+// Excluded reports whether the user left the save out of sync.
+func (r SyncReason) Excluded() bool {
+	return r == ReasonExcludedGame || r == ReasonExcludedPlatform
+}
+
+// Size returns the number of bytes the sync will transfer.
+func (s SaveSync) Size() int64 {
+	switch s.Action {
+	case Upload:
+		if s.Local != nil {
+			return s.Local.Size
+		}
+	case Download, Conflict:
+		return int64(s.Remote.FileSizeBytes)
+	}
+	return 0
+}
+
 type SyncResult struct {
 	GameName       string
 	RomDisplayName string
 	Action         SyncAction
+	Reason         SyncReason
 	Success        bool
 	Error          string
 	FilePath       string
+	Device         string
 	UnmatchedSaves []UnmatchedSave
 }
 
@@ -60,6 +108,7 @@ func (s *SaveSync) Execute(host romm.Host, config *internal.Config) SyncResult {
 		GameName:       s.GameBase,
 		RomDisplayName: displayName,
 		Action:         s.Action,
+		Reason:         s.Reason,
 		Success:        false,
 	}
 
@@ -72,6 +121,9 @@ func (s *SaveSync) Execute(host romm.Host, config *internal.Config) SyncResult {
 	var err error
 	switch s.Action {
 	case Upload:
+		if config != nil {
+			result.Device = config.DeviceName
+		}
 		result.FilePath, err = s.upload(host, config)
 		logger.Debug("Upload complete", "filePath", result.FilePath, "err", err)
 	case Download:
@@ -82,7 +134,11 @@ func (s *SaveSync) Execute(host romm.Host, config *internal.Config) SyncResult {
 				return result
 			}
 		}
+		result.Device = SaveDeviceName(s.Remote)
 		result.FilePath, err = s.download(host, config)
+	case Conflict:
+		result.Device = SaveDeviceName(s.Remote)
+		result.FilePath, err = s.keepConflictCopy(host, config)
 	case Skip:
 		result.Success = true
 		return result
@@ -90,8 +146,10 @@ func (s *SaveSync) Execute(host romm.Host, config *internal.Config) SyncResult {
 
 	if err != nil {
 		result.Error = err.Error()
+		queueFailedSync(*s, result.Error)
 	} else {
 		result.Success = true
+		dequeueSync(*s)
 	}
 
 	return result
@@ -104,17 +162,26 @@ func (s *SaveSync) download(host romm.Host, config *internal.Config) (string, er
 	}
 	rc := romm.NewClientFromHost(host, config.ApiTimeout)
 
-	logger.Debug("Downloading save", "saveID", s.Remote.ID, "downloadPath", s.Remote.DownloadPath)
+	logger.Debug("Downloading save", "saveID", s.Remote.ID, "downloadPath", s.Remote.DownloadPath, "device", SaveDeviceName(s.Remote))
 
 	saveData, err := rc.DownloadSave(s.Remote.DownloadPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to download save: %w", err)
 	}
 
+	if layout := cfw.SaveLayoutForFSSlug(s.FSSlug).FolderSaves; layout != nil && normalizeExt(s.Remote.FileExtension) == ".zip" {
+		return s.downloadFolderSave(saveData, layout, config)
+	}
+
 	var destDir string
 	if s.Local != nil {
 		// If there's already a local save, use its directory
 		destDir = filepath.Dir(s.Local.Path)
+	} else if s.SharedCard {
+		destDir, err = sharedCardDir(s.FSSlug, s.Remote, config)
+		if err != nil {
+			return "", fmt.Errorf("cannot determine save location: %w", err)
+		}
 	} else {
 		var err error
 		destDir, err = ResolveSavePath(s.FSSlug, s.RomID, config)
@@ -124,18 +191,35 @@ func (s *SaveSync) download(host romm.Host, config *internal.Config) (string, er
 	}
 
 	ext := normalizeExt(s.Remote.FileExtension)
-	filename := s.GameBase + ext
+
+	// The save may come from a different core than the one this folder belongs to
+	saveData, ext, err = convertSave(s.FSSlug, s.Remote.Emulator, filepath.Base(destDir), saveData, ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert save: %w", err)
+	}
+
+	gameBase := s.GameBase
+	if s.Local == nil && s.DiscBase != "" && cfw.NamesSavesAfterFirstDisc(s.FSSlug, filepath.Base(destDir)) {
+		gameBase = s.DiscBase
+	}
+
+	filename := gameBase + ext
 	destPath := filepath.Join(destDir, filename)
 
 	if s.Local != nil && s.Local.Path != destPath {
 		defer func() { _ = os.Remove(s.Local.Path) }()
 	}
 
-	err = os.WriteFile(destPath, saveData, 0644)
+	ownWrites.add(destPath)
+	defer ownWrites.add(destPath)
+
+	err = fileutil.WriteFileAtomic(destPath, saveData, 0644)
 	if err != nil {
 		return "", fmt.Errorf("failed to write save file: %w", err)
 	}
 
+	recordSyncState(destPath, s.RomID, hashSaveData(saveData), s.Remote)
+
 	err = os.Chtimes(destPath, s.Remote.UpdatedAt, s.Remote.UpdatedAt)
 	if err != nil {
 		return "", fmt.Errorf("failed to update file timestamp: %w", err)
@@ -148,6 +232,50 @@ func (s *SaveSync) download(host romm.Host, config *internal.Config) (string, er
 	return destPath, nil
 }
 
+// downloadFolderSave unpacks an archived folder save into the emulator's save root.
+func (s *SaveSync) downloadFolderSave(saveData []byte, layout *cfw.FolderSaveLayout, config *internal.Config) (string, error) {
+	var emulatorDir, root string
+	if s.Local != nil && s.Local.Folder != nil {
+		emulatorDir = s.Local.Folder.EmulatorDir
+		root = s.Local.Folder.Root
+	} else {
+		var err error
+		emulatorDir, err = ResolveSavePath(s.FSSlug, s.RomID, config)
+		if err != nil {
+			return "", fmt.Errorf("cannot determine save location: %w", err)
+		}
+
+		var found bool
+		if root, found = existingFolderSaveRoot(layout, emulatorDir); !found {
+			root = filepath.Join(emulatorDir, filepath.FromSlash(layout.Roots[0]))
+			if err := os.MkdirAll(root, 0755); err != nil {
+				return "", fmt.Errorf("failed to create save directory: %w", err)
+			}
+		}
+	}
+
+	ownWrites.add(root)
+	defer ownWrites.add(root)
+
+	gameID, err := unpackFolderSave(saveData, root, s.Remote.UpdatedAt)
+	if err != nil {
+		return "", fmt.Errorf("failed to unpack save folder: %w", err)
+	}
+
+	// Record the hash of what is on disk now, since that is what the next scan will compare
+	local, err := reloadFolderSave(s.FSSlug, emulatorDir, root, gameID)
+	if err != nil {
+		return "", err
+	}
+	if contentHash, err := local.hash(); err == nil {
+		recordSyncState(local.Path, s.RomID, contentHash, s.Remote)
+	}
+
+	gaba.GetLogger().Debug("Unpacked folder save", "root", root, "gameID", gameID, "dirs", local.Folder.Dirs)
+
+	return local.Path, nil
+}
+
 func (s *SaveSync) upload(host romm.Host, config *internal.Config) (string, error) {
 	if s.Local == nil {
 		return "", fmt.Errorf("cannot upload: no local save file")
@@ -159,38 +287,83 @@ func (s *SaveSync) upload(host romm.Host, config *internal.Config) (string, erro
 	rc := romm.NewClientFromHost(host, config.ApiTimeout)
 
 	ext := normalizeExt(filepath.Ext(s.Local.Path))
+	modTime := s.Local.LastModified
 
-	fileInfo, err := os.Stat(s.Local.Path)
-	if err != nil {
-		return "", fmt.Errorf("failed to get file info: %w", err)
+	if s.Local.Folder != nil {
+		ext = ".zip"
+	} else {
+		fileInfo, err := os.Stat(s.Local.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to get file info: %w", err)
+		}
+		modTime = fileInfo.ModTime()
 	}
-	modTime := fileInfo.ModTime()
 	timestamp := modTime.Format("[2006-01-02 15-04-05-000]")
 
-	filename := s.GameBase + " " + timestamp + ext
-	tmp := filepath.Join(fileutil.TempDir(), "uploads", filename)
-
-	err = fileutil.CopyFile(s.Local.Path, tmp)
-	if err != nil {
-		return "", err
+	filename := s.GameBase + " " + timestamp
+	if tag := deviceTag(config); tag != "" {
+		filename += " " + tag
 	}
+	filename += ext
+	tmp := filepath.Join(fileutil.TempDir(), "uploads", filename)
 
 	// Get emulator from the save folder path
 	emulator := filepath.Base(filepath.Dir(s.Local.Path))
 
+	if s.Local.Folder != nil {
+		data, err := s.Local.Folder.pack()
+		if err != nil {
+			return "", fmt.Errorf("failed to archive save folder: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(tmp), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return "", err
+		}
+		emulator = filepath.Base(s.Local.Folder.EmulatorDir)
+	} else if err := fileutil.CopyFile(s.Local.Path, tmp); err != nil {
+		return "", err
+	}
+
 	uploadedSave, err := rc.UploadSave(s.RomID, tmp, emulator)
 	if err != nil {
 		return "", err
 	}
 
-	err = os.Chtimes(s.Local.Path, uploadedSave.UpdatedAt, uploadedSave.UpdatedAt)
-	if err != nil {
-		return "", fmt.Errorf("failed to update file timestamp: %w", err)
+	// Hash the copy that was actually sent, in case the save changed since the scan
+	if contentHash, err := fileutil.HashFile(tmp, sha1.New()); err == nil {
+		recordSyncState(s.Local.Path, s.RomID, contentHash, uploadedSave)
+	}
+
+	pruneAfterUpload(rc, config, uploadedSave)
+
+	// Folder saves have no single file to stamp; their sync state is enough
+	if s.Local.Folder == nil {
+		err = os.Chtimes(s.Local.Path, uploadedSave.UpdatedAt, uploadedSave.UpdatedAt)
+		if err != nil {
+			return "", fmt.Errorf("failed to update file timestamp: %w", err)
+		}
 	}
 
 	return s.Local.Path, nil
 }
 
+// recordSyncState remembers what a save looked like after a successful transfer so the
+// next scan can tell real changes apart from clock drift.
+func recordSyncState(savePath string, romID int, contentHash string, remote romm.Save) {
+	err := cache.GetCacheManager().SetSaveSyncState(cache.SaveSyncState{
+		SavePath:        savePath,
+		RomID:           romID,
+		ContentHash:     contentHash,
+		RemoteSaveID:    remote.ID,
+		RemoteUpdatedAt: remote.UpdatedAt,
+	})
+	if err != nil {
+		gaba.GetLogger().Debug("Unable to record save sync state", "path", savePath, "error", err)
+	}
+}
+
 // lookupRomID looks up a ROM ID by filename from the cache
 func lookupRomID(romFile *LocalRomFile) (int, string) {
 	logger := gaba.GetLogger()
@@ -219,20 +392,10 @@ func FindSaveSyncsFromScan(host romm.Host, config *internal.Config, scanLocal Lo
 	logger.Debug("FindSaveSyncs: Scanned local ROMs", "platformCount", len(scanLocal))
 
 	// Get platforms from cache or API to build fsSlug -> platformID map
-	cm := cache.GetCacheManager()
-	var platforms []romm.Platform
-	var err error
-
-	if cm != nil {
-		platforms, err = cm.GetPlatforms()
-	}
-	if err != nil || len(platforms) == 0 {
-		// Fall back to API if cache miss
-		platforms, err = rc.GetPlatforms()
-		if err != nil {
-			logger.Error("FindSaveSyncs: Could not retrieve platforms", "error", err)
-			return []SaveSync{}, nil, err
-		}
+	platforms, err := getPlatforms(rc)
+	if err != nil {
+		logger.Error("FindSaveSyncs: Could not retrieve platforms", "error", err)
+		return []SaveSync{}, nil, err
 	}
 
 	fsSlugToPlatformID := make(map[string]int)
@@ -240,62 +403,12 @@ func FindSaveSyncsFromScan(host romm.Host, config *internal.Config, scanLocal Lo
 		fsSlugToPlatformID[p.FSSlug] = p.ID
 	}
 
-	// Fetch saves per platform in parallel (saves are not cached - always fresh from API)
-	type platformFetchResult struct {
-		fsSlug   string
-		saves    []romm.Save
-		hasError bool
-	}
-
-	resultChan := make(chan platformFetchResult, len(scanLocal))
-	var wg gosync.WaitGroup
-
+	fsSlugs := make([]string, 0, len(scanLocal))
 	for fsSlug := range scanLocal {
-		platformID, ok := fsSlugToPlatformID[fsSlug]
-		if !ok {
-			logger.Debug("FindSaveSyncs: No platform ID for fsSlug", "fsSlug", fsSlug)
-			continue
-		}
-
-		wg.Add(1)
-		go func(fsSlug string, platformID int) {
-			defer wg.Done()
-
-			result := platformFetchResult{
-				fsSlug: fsSlug,
-			}
-
-			// Fetch saves for this platform (always from API - saves need to be fresh)
-			platformSaves, err := rc.GetSaves(romm.SaveQuery{PlatformID: platformID})
-			if err != nil {
-				logger.Warn("FindSaveSyncs: Could not retrieve saves for platform", "fsSlug", fsSlug, "error", err)
-				result.hasError = true
-				resultChan <- result
-				return
-			}
-			result.saves = platformSaves
-			logger.Debug("FindSaveSyncs: Retrieved saves for platform", "fsSlug", fsSlug, "count", len(platformSaves))
-
-			resultChan <- result
-		}(fsSlug, platformID)
-	}
-
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	// Collect saves by ROM ID
-	savesByRomID := make(map[int][]romm.Save)
-	for result := range resultChan {
-		if result.hasError {
-			continue
-		}
-
-		for _, s := range result.saves {
-			savesByRomID[s.RomID] = append(savesByRomID[s.RomID], s)
-		}
+		fsSlugs = append(fsSlugs, fsSlug)
 	}
+	savesByPlatform := fetchPlatformSaves(rc, fsSlugs, fsSlugToPlatformID)
+	savesByRomID := groupSavesByRomID(savesByPlatform)
 
 	// Match local ROMs to cached ROMs by filename
 	var unmatched []UnmatchedSave
@@ -311,8 +424,14 @@ func FindSaveSyncsFromScan(host romm.Host, config *internal.Config, scanLocal Lo
 			// Look up ROM ID from the games cache
 			romID, romName := lookupRomID(romFile)
 
+			// Renamed ROMs won't match by filename. Only those with a local save are worth
+			// hashing, since large disc images are slow to read on a handheld.
+			if romID == 0 && romFile.SaveFile != nil && !config.IsPlatformSyncExcluded(fsSlug) {
+				romID, romName = lookupRomIDByHash(rc, romFile)
+			}
+
 			if romID == 0 {
-				if romFile.SaveFile != nil {
+				if romFile.SaveFile != nil && !config.IsPlatformSyncExcluded(fsSlug) {
 					unmatched = append(unmatched, UnmatchedSave{
 						SavePath: romFile.SaveFile.Path,
 						FSSlug:   fsSlug,
@@ -347,9 +466,26 @@ func FindSaveSyncsFromScan(host romm.Host, config *internal.Config, scanLocal Lo
 					"hasLocalSave", r.SaveFile != nil,
 					"remoteSaveCount", len(r.RemoteSaves))
 			}
-			action := r.syncAction()
-			if action == Upload || action == Download {
+			action, reason := r.syncAction()
+
+			// Excluded games are listed as skipped, but only if there was something to sync
+			excluded := false
+			if excludedReason, ok := syncExclusion(config, fsSlug, r.RomID); ok {
+				if r.RomID == 0 || (r.SaveFile == nil && len(r.RemoteSaves) == 0) {
+					continue
+				}
+				action, reason, excluded = Skip, excludedReason, true
+			}
+
+			// Saves that are already in sync are kept in the plan so they can be previewed
+			inSync := action == Skip && r.RomID > 0 && r.SaveFile != nil && len(r.RemoteSaves) > 0
+
+			if action == Upload || action == Download || inSync || excluded {
 				baseName := strings.TrimSuffix(r.FileName, filepath.Ext(r.FileName))
+				// A multi-disc save may be named after a disc rather than the playlist; keep its name
+				if len(r.Discs) > 0 && r.SaveFile != nil && r.SaveFile.Folder == nil {
+					baseName = strings.TrimSuffix(filepath.Base(r.SaveFile.Path), filepath.Ext(r.SaveFile.Path))
+				}
 
 				// Create unique key for deduplication
 				var key string
@@ -371,9 +507,11 @@ func FindSaveSyncsFromScan(host romm.Host, config *internal.Config, scanLocal Lo
 					RomName:  r.RomName,
 					FSSlug:   fsSlug,
 					GameBase: baseName,
+					DiscBase: r.firstDiscBase(),
 					Local:    r.SaveFile,
 					Remote:   r.lastRemoteSave(),
 					Action:   action,
+					Reason:   reason,
 				}
 			}
 		}
@@ -384,6 +522,8 @@ func FindSaveSyncsFromScan(host romm.Host, config *internal.Config, scanLocal Lo
 		syncs = append(syncs, s)
 	}
 
+	syncs = append(syncs, findSharedCardSyncs(config, scanLocal, savesByPlatform)...)
+
 	if len(unmatched) > 0 {
 		logger.Info("Unmatched saves", "count", len(unmatched))
 	}
@@ -391,6 +531,113 @@ func FindSaveSyncsFromScan(host romm.Host, config *internal.Config, scanLocal Lo
 	return syncs, unmatched, nil
 }
 
+// syncExclusion reports whether the user left a game out of save sync, either on its own
+// or through its platform.
+func syncExclusion(config *internal.Config, fsSlug string, romID int) (SyncReason, bool) {
+	switch {
+	case config.IsPlatformSyncExcluded(fsSlug):
+		return ReasonExcludedPlatform, true
+	case config.IsGameSyncExcluded(romID):
+		return ReasonExcludedGame, true
+	default:
+		return "", false
+	}
+}
+
+// getPlatforms returns platforms from the cache, falling back to the API on a cache miss.
+func getPlatforms(rc *romm.Client) ([]romm.Platform, error) {
+	var platforms []romm.Platform
+	var err error
+
+	if cm := cache.GetCacheManager(); cm != nil {
+		platforms, err = cm.GetPlatforms()
+	}
+	if err != nil || len(platforms) == 0 {
+		platforms, err = rc.GetPlatforms()
+	}
+
+	return platforms, err
+}
+
+// fetchSavesByRomID fetches saves for each platform in parallel and groups them by ROM ID.
+// Saves are not cached - they always come fresh from the API.
+func fetchSavesByRomID(rc *romm.Client, fsSlugs []string, fsSlugToPlatformID map[string]int) map[int][]romm.Save {
+	return groupSavesByRomID(fetchPlatformSaves(rc, fsSlugs, fsSlugToPlatformID))
+}
+
+// fetchPlatformSaves fetches saves for each platform in parallel, keyed by fs_slug.
+func fetchPlatformSaves(rc *romm.Client, fsSlugs []string, fsSlugToPlatformID map[string]int) map[string][]romm.Save {
+	logger := gaba.GetLogger()
+
+	type platformFetchResult struct {
+		fsSlug   string
+		saves    []romm.Save
+		hasError bool
+	}
+
+	resultChan := make(chan platformFetchResult, len(fsSlugs))
+	var wg gosync.WaitGroup
+
+	for _, fsSlug := range fsSlugs {
+		platformID, ok := fsSlugToPlatformID[fsSlug]
+		if !ok {
+			logger.Debug("FindSaveSyncs: No platform ID for fsSlug", "fsSlug", fsSlug)
+			continue
+		}
+
+		wg.Add(1)
+		go func(fsSlug string, platformID int) {
+			defer wg.Done()
+
+			result := platformFetchResult{
+				fsSlug: fsSlug,
+			}
+
+			platformSaves, err := rc.GetSaves(romm.SaveQuery{PlatformID: platformID})
+			if err != nil {
+				logger.Warn("FindSaveSyncs: Could not retrieve saves for platform", "fsSlug", fsSlug, "error", err)
+				result.hasError = true
+				resultChan <- result
+				return
+			}
+			result.saves = platformSaves
+			logger.Debug("FindSaveSyncs: Retrieved saves for platform", "fsSlug", fsSlug, "count", len(platformSaves))
+
+			resultChan <- result
+		}(fsSlug, platformID)
+	}
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	savesByPlatform := make(map[string][]romm.Save)
+	for result := range resultChan {
+		if result.hasError {
+			continue
+		}
+		savesByPlatform[result.fsSlug] = result.saves
+	}
+
+	return savesByPlatform
+}
+
+// groupSavesByRomID groups per-game saves by ROM ID. Shared memory cards are left out,
+// since the ROM they were uploaded against doesn't own them.
+func groupSavesByRomID(savesByPlatform map[string][]romm.Save) map[int][]romm.Save {
+	savesByRomID := make(map[int][]romm.Save)
+	for fsSlug, saves := range savesByPlatform {
+		for _, s := range saves {
+			if cfw.IsSharedCard(fsSlug, untaggedSaveName(s)) {
+				continue
+			}
+			savesByRomID[s.RomID] = append(savesByRomID[s.RomID], s)
+		}
+	}
+	return savesByRomID
+}
+
 // normalizeExt ensures the extension has a leading dot
 func normalizeExt(ext string) string {
 	if ext != "" && !strings.HasPrefix(ext, ".") {
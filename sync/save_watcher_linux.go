@@ -0,0 +1,146 @@
+package sync
+
+import (
+	"encoding/binary"
+	"errors"
+	"grout/internal/fileutil"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// saveWatchMask asks for files finished being written or moved in, and for new folders
+// so that games creating their own save folder (PSP SAVEDATA) are watched too.
+const saveWatchMask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO | syscall.IN_CREATE
+
+// saveWatcher reports files written into the save folders, and the folders below them,
+// using inotify.
+type saveWatcher struct {
+	fd      int
+	file    *os.File
+	watches map[int32]string
+	events  chan string
+}
+
+func newSaveWatcher(dirs []string) (*saveWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wrapping the non-blocking descriptor lets the runtime poller park Read, and
+	// lets Close interrupt it.
+	w := &saveWatcher{
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		watches: make(map[int32]string),
+		events:  make(chan string, 64),
+	}
+
+	for _, dir := range dirs {
+		if fileutil.FileExists(dir) {
+			w.watchTree(dir)
+		}
+	}
+
+	if len(w.watches) == 0 {
+		w.file.Close()
+		return nil, errors.New("no save folders to watch")
+	}
+
+	go w.readLoop()
+
+	return w, nil
+}
+
+// watchTree watches dir and every folder below it, leaving out hidden folders such as
+// the backups a sync writes.
+func (w *saveWatcher) watchTree(dir string) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		wd, err := syscall.InotifyAddWatch(w.fd, path, saveWatchMask)
+		if err != nil {
+			gaba.GetLogger().Warn("Unable to watch save folder", "path", path, "error", err)
+			return nil
+		}
+		w.watches[int32(wd)] = path
+		return nil
+	})
+}
+
+func (w *saveWatcher) Events() <-chan string {
+	return w.events
+}
+
+func (w *saveWatcher) Close() error {
+	return w.file.Close()
+}
+
+func (w *saveWatcher) readLoop() {
+	defer close(w.events)
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			wd := int32(binary.NativeEndian.Uint32(buf[offset:]))
+			mask := binary.NativeEndian.Uint32(buf[offset+4:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[offset+12:]))
+
+			nameStart := offset + syscall.SizeofInotifyEvent
+			offset = nameStart + nameLen
+			if offset > n {
+				break
+			}
+
+			if mask&syscall.IN_Q_OVERFLOW != 0 {
+				w.send("")
+				continue
+			}
+
+			name := strings.TrimRight(string(buf[nameStart:nameStart+nameLen]), "\x00")
+			if name == "" || strings.HasPrefix(name, ".") {
+				continue
+			}
+
+			dir, ok := w.watches[wd]
+			if !ok {
+				continue
+			}
+			path := filepath.Join(dir, name)
+
+			// Files are reported once written; a new folder is watched and reported, since
+			// a game may have written into it before the watch was added
+			if mask&syscall.IN_CREATE != 0 {
+				if mask&syscall.IN_ISDIR == 0 {
+					continue
+				}
+				w.watchTree(path)
+			}
+			w.send(path)
+		}
+	}
+}
+
+// send drops the event when the scheduler is behind; it only needs to know that
+// something changed, not every file that did.
+func (w *saveWatcher) send(path string) {
+	select {
+	case w.events <- path:
+	default:
+	}
+}
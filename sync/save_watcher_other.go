@@ -0,0 +1,19 @@
+//go:build !linux
+
+package sync
+
+// saveWatcher is unavailable off Linux. The scheduler still runs on its interval
+// and on network changes.
+type saveWatcher struct{}
+
+func newSaveWatcher(dirs []string) (*saveWatcher, error) {
+	return nil, errSaveWatchUnsupported
+}
+
+func (w *saveWatcher) Events() <-chan string {
+	return nil
+}
+
+func (w *saveWatcher) Close() error {
+	return nil
+}
@@ -1,7 +1,10 @@
 package sync
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
+	"grout/cache"
 	"grout/cfw"
 	"grout/internal"
 	"grout/internal/fileutil"
@@ -20,6 +23,10 @@ type LocalSave struct {
 	FSSlug       string
 	Path         string
 	LastModified time.Time
+	Size         int64
+	Folder       *FolderSave
+
+	contentHash string
 }
 
 type EmulatorDirectoryInfo struct {
@@ -38,13 +45,125 @@ func (lc LocalSave) timestampedFilename() string {
 	return fmt.Sprintf("%s [%s]%s", base, lm, ext)
 }
 
+// hash returns the SHA-1 of the save contents, computing it on first use.
+func (lc *LocalSave) hash() (string, error) {
+	if lc.contentHash != "" {
+		return lc.contentHash, nil
+	}
+
+	var h string
+	if lc.Folder != nil {
+		data, err := lc.Folder.pack()
+		if err != nil {
+			return "", err
+		}
+		h = hashSaveData(data)
+	} else {
+		var err error
+		h, err = fileutil.HashFile(lc.Path, sha1.New())
+		if err != nil {
+			return "", err
+		}
+	}
+
+	lc.contentHash = h
+	return h, nil
+}
+
+func hashSaveData(data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
 func (lc LocalSave) backup() error {
+	if lc.Folder != nil {
+		data, err := lc.Folder.pack()
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(lc.Folder.Root, ".backup", lc.timestampedFilename()+".zip")
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		return fileutil.WriteFileAtomic(dest, data, 0644)
+	}
+
 	dest := filepath.Join(filepath.Dir(lc.Path), ".backup", lc.timestampedFilename())
 	return fileutil.CopyFile(lc.Path, dest)
 }
 
+// remove deletes the save from the device.
+func (lc LocalSave) remove() error {
+	if lc.Folder == nil {
+		return os.Remove(lc.Path)
+	}
+
+	for _, dir := range lc.Folder.Dirs {
+		if err := os.RemoveAll(filepath.Join(lc.Folder.Root, dir)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenameGameSaves renames the saves of a ROM whose file name changed, such as after an
+// update from RomM, so the emulator still finds them. Saves already present under the
+// new name are left as they are. It returns how many saves were renamed.
+func RenameGameSaves(fsSlug, oldBase, newBase string) int {
+	logger := gaba.GetLogger()
+	cm := cache.GetCacheManager()
+
+	renamed := 0
+	for _, save := range findSaveFiles(fsSlug) {
+		name := filepath.Base(save.Path)
+		if save.Folder != nil || cfw.IsSharedCard(fsSlug, name) || strings.TrimSuffix(name, filepath.Ext(name)) != oldBase {
+			continue
+		}
+
+		newPath := filepath.Join(filepath.Dir(save.Path), newBase+filepath.Ext(name))
+		if fileutil.FileExists(newPath) {
+			logger.Debug("Save already exists under the new name", "path", newPath)
+			continue
+		}
+
+		if err := os.Rename(save.Path, newPath); err != nil {
+			logger.Error("Failed to rename save", "from", save.Path, "to", newPath, "error", err)
+			continue
+		}
+
+		if state, ok := cm.GetSaveSyncState(save.Path); ok {
+			state.SavePath = newPath
+			if err := cm.SetSaveSyncState(state); err == nil {
+				_ = cm.DeleteSaveSyncState(save.Path)
+			}
+		}
+
+		logger.Info("Renamed save", "from", save.Path, "to", newPath)
+		renamed++
+	}
+
+	return renamed
+}
+
 func ResolveSavePath(fsSlug string, gameID int, config *internal.Config) (string, error) {
 	logger := gaba.GetLogger()
+
+	saveDir, err := saveFolderPath(fsSlug, gameID, config)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(saveDir, 0755); err != nil {
+		logger.Error("Failed to create save directory", "path", saveDir, "error", err)
+		return "", fmt.Errorf("failed to create save directory: %w", err)
+	}
+
+	return saveDir, nil
+}
+
+// saveFolderPath picks the save directory for a game without creating it.
+func saveFolderPath(fsSlug string, gameID int, config *internal.Config) (string, error) {
+	logger := gaba.GetLogger()
 	logger.Debug("ResolveSavePath called", "fsSlug", fsSlug, "gameID", gameID)
 	basePath := cfw.BaseSavePath()
 
@@ -65,7 +184,7 @@ func ResolveSavePath(fsSlug string, gameID int, config *internal.Config) (string
 				if folder == override {
 					selectedFolder = override
 					logger.Debug("Using per-game override", "gameID", gameID, "folder", override)
-					goto createDir
+					goto resolved
 				}
 			}
 			logger.Warn("Per-game override not valid for fsSlug, ignoring", "gameID", gameID, "override", override, "fsSlug", fsSlug)
@@ -79,23 +198,16 @@ func ResolveSavePath(fsSlug string, gameID int, config *internal.Config) (string
 				if folder == mapping {
 					selectedFolder = mapping
 					logger.Debug("Using platform mapping from config", "fsSlug", fsSlug, "folder", mapping)
-					goto createDir
+					goto resolved
 				}
 			}
 			logger.Warn("Platform mapping not valid for fsSlug, ignoring", "mapping", mapping, "fsSlug", fsSlug)
 		}
 	}
 
-createDir:
+resolved:
 	logger.Debug("Final selectedFolder", "selectedFolder", selectedFolder)
-	saveDir := filepath.Join(basePath, selectedFolder)
-
-	if err := os.MkdirAll(saveDir, 0755); err != nil {
-		logger.Error("Failed to create save directory", "path", saveDir, "error", err)
-		return "", fmt.Errorf("failed to create save directory: %w", err)
-	}
-
-	return saveDir, nil
+	return filepath.Join(basePath, selectedFolder), nil
 }
 
 func findSaveFiles(fsSlug string) []LocalSave {
@@ -156,6 +268,7 @@ func findSaveFiles(fsSlug string) []LocalSave {
 					FSSlug:       fsSlug,
 					Path:         savePath,
 					LastModified: fileInfo.ModTime(),
+					Size:         fileInfo.Size(),
 				}
 
 				result.saves = append(result.saves, saveFile)
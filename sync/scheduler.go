@@ -0,0 +1,273 @@
+package sync
+
+import (
+	"errors"
+	"grout/cfw"
+	"grout/internal"
+	"net"
+	"path/filepath"
+	"sort"
+	"strings"
+	gosync "sync"
+	"time"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+const (
+	// saveChangeDebounce is how long the save folders must stay quiet before a change
+	// triggers a sync. Emulators often write a save in several passes.
+	saveChangeDebounce = 5 * time.Second
+
+	// networkCheckInterval is how often the scheduler looks for a reconnect and checks
+	// for queued retries that are due.
+	networkCheckInterval = 30 * time.Second
+
+	// ownWriteGrace is how long after a sync writes a save its change events are ignored.
+	ownWriteGrace = 5 * time.Second
+)
+
+var errSaveWatchUnsupported = errors.New("save folder watching is not supported on this platform")
+
+// ownWrites remembers the saves a sync has just written, so the scheduler doesn't take
+// them for the player's changes and sync again.
+var ownWrites = &writtenPaths{paths: make(map[string]time.Time)}
+
+type writtenPaths struct {
+	mu    gosync.Mutex
+	paths map[string]time.Time
+}
+
+// add records a write to path, or to anything under it when path is a folder. Writers
+// call it both before and after writing, so late events still fall within the grace.
+func (w *writtenPaths) add(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	for p, at := range w.paths {
+		if now.Sub(at) > ownWriteGrace {
+			delete(w.paths, p)
+		}
+	}
+	w.paths[path] = now
+}
+
+func (w *writtenPaths) contains(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for p, at := range w.paths {
+		if time.Since(at) > ownWriteGrace {
+			continue
+		}
+		if path == p || strings.HasPrefix(path, p+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SyncScheduler re-runs AutoSync while Grout is open: on the configured interval,
+// when the network comes back after an outage, and after save files change on disk.
+type SyncScheduler struct {
+	autoSync  *AutoSync
+	interval  time.Duration
+	watchDirs []string
+	intervals chan time.Duration
+	stop      chan struct{}
+	stopOnce  gosync.Once
+}
+
+// NewSyncScheduler reads what it needs from config up front; the scheduler runs in its
+// own goroutine and is told about a changed interval through SetInterval.
+func NewSyncScheduler(autoSync *AutoSync, config *internal.Config) *SyncScheduler {
+	return &SyncScheduler{
+		autoSync:  autoSync,
+		interval:  config.SaveSyncInterval,
+		watchDirs: saveWatchDirs(config),
+		intervals: make(chan time.Duration, 1),
+		stop:      make(chan struct{}),
+	}
+}
+
+func (s *SyncScheduler) Start() {
+	go s.run()
+}
+
+func (s *SyncScheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+// SetInterval changes how often the scheduler syncs. Only the latest value is kept if the
+// scheduler hasn't picked up an earlier one yet.
+func (s *SyncScheduler) SetInterval(interval time.Duration) {
+	for {
+		select {
+		case s.intervals <- interval:
+			return
+		default:
+		}
+		select {
+		case <-s.intervals:
+		default:
+		}
+	}
+}
+
+func (s *SyncScheduler) run() {
+	logger := gaba.GetLogger()
+
+	interval := s.interval
+	intervalTicker := newIntervalTicker(interval)
+	defer func() {
+		if intervalTicker != nil {
+			intervalTicker.Stop()
+		}
+	}()
+
+	networkTicker := time.NewTicker(networkCheckInterval)
+	defer networkTicker.Stop()
+	online := hasNetwork()
+
+	var changes <-chan string
+	watcher, err := newSaveWatcher(s.watchDirs)
+	if err != nil {
+		logger.Info("SyncScheduler: Not watching save folders", "error", err)
+	} else {
+		defer watcher.Close()
+		changes = watcher.Events()
+	}
+
+	debounce := time.NewTimer(saveChangeDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	logger.Debug("SyncScheduler: Started", "interval", interval, "watching", watcher != nil)
+
+	for {
+		select {
+		case <-s.stop:
+			logger.Debug("SyncScheduler: Stopped")
+			return
+
+		case <-tickerChan(intervalTicker):
+			s.trigger("interval")
+
+		case current := <-s.intervals:
+			if current == interval {
+				continue
+			}
+			if intervalTicker != nil {
+				intervalTicker.Stop()
+			}
+			interval = current
+			intervalTicker = newIntervalTicker(interval)
+			logger.Debug("SyncScheduler: Interval changed", "interval", interval)
+
+		case <-networkTicker.C:
+			nowOnline := hasNetwork()
+			if nowOnline && !online {
+				s.trigger("network reconnected")
+			} else if nowOnline && HasDueQueuedSyncs() {
+				s.trigger("queued retry")
+			}
+			online = nowOnline
+
+		case path, ok := <-changes:
+			if !ok {
+				changes = nil
+				continue
+			}
+			if ownWrites.contains(path) {
+				continue
+			}
+			// The running sync may have scanned before this write landed, so it goes
+			// round once more
+			if s.autoSync.RequestRerun() {
+				continue
+			}
+			logger.Debug("SyncScheduler: Save changed", "path", path)
+			debounce.Reset(saveChangeDebounce)
+
+		case <-debounce.C:
+			if !s.trigger("save changed") && !s.autoSync.RequestRerun() {
+				debounce.Reset(saveChangeDebounce)
+			}
+		}
+	}
+}
+
+func (s *SyncScheduler) trigger(reason string) bool {
+	if !s.autoSync.Trigger() {
+		gaba.GetLogger().Debug("SyncScheduler: Sync already running", "reason", reason)
+		return false
+	}
+	gaba.GetLogger().Debug("SyncScheduler: Triggered sync", "reason", reason)
+	return true
+}
+
+func newIntervalTicker(interval time.Duration) *time.Ticker {
+	if interval <= 0 {
+		return nil
+	}
+	return time.NewTicker(interval)
+}
+
+// tickerChan returns a nil channel for a disabled ticker so its select case never fires.
+func tickerChan(t *time.Ticker) <-chan time.Time {
+	if t == nil {
+		return nil
+	}
+	return t.C
+}
+
+// hasNetwork reports whether any non-loopback interface is up with a routable address.
+func hasNetwork() bool {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return false
+	}
+
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.IsGlobalUnicast() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// saveWatchDirs lists the save folders of every mapped platform, plus the base save folder.
+// The watcher also watches the folders inside them.
+func saveWatchDirs(config *internal.Config) []string {
+	basePath := cfw.BaseSavePath()
+	seen := map[string]bool{basePath: true}
+
+	for fsSlug := range config.DirectoryMappings {
+		for _, folder := range cfw.EmulatorFoldersForFSSlug(fsSlug) {
+			seen[filepath.Join(basePath, folder)] = true
+		}
+	}
+
+	dirs := make([]string, 0, len(seen))
+	for dir := range seen {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	return dirs
+}
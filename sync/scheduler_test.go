@@ -0,0 +1,35 @@
+package sync
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWrittenPaths(t *testing.T) {
+	root := filepath.Join("saves", "PSP", "SAVEDATA")
+	w := &writtenPaths{paths: make(map[string]time.Time)}
+	w.add(root)
+	w.add(filepath.Join("saves", "GBA", "game.sav"))
+	w.paths[filepath.Join("saves", "GBA", "old.sav")] = time.Now().Add(-2 * ownWriteGrace)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{root, true},
+		{filepath.Join(root, "ULUS10041", "DATA.BIN"), true},
+		{filepath.Join("saves", "GBA", "game.sav"), true},
+		{filepath.Join("saves", "GBA", "other.sav"), false},
+		{filepath.Join("saves", "GBA", "old.sav"), false},
+		{root + "2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := w.contains(tt.path); got != tt.want {
+				t.Errorf("contains(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"grout/cache"
+	"grout/cfw"
+	"grout/internal"
+	"grout/internal/fileutil"
+	"grout/romm"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+)
+
+// sharedCardKey identifies a card by emulator folder and file name, since two cores can
+// each keep a card with the same name.
+func sharedCardKey(emulator, fileName string) string {
+	return emulator + "/" + fileName
+}
+
+// findSharedCardSyncs plans syncs for the shared memory cards of every scanned platform.
+// Cards are matched to RomM by emulator and file name rather than by ROM, and uploaded
+// against one of the platform's ROMs because RomM only stores saves per ROM.
+func findSharedCardSyncs(config *internal.Config, scanLocal LocalRomScan, savesByPlatform map[string][]romm.Save) []SaveSync {
+	logger := gaba.GetLogger()
+	var syncs []SaveSync
+
+	for fsSlug, roms := range scanLocal {
+		if len(cfw.SaveLayoutForFSSlug(fsSlug).SharedCards) == 0 || config.IsPlatformSyncExcluded(fsSlug) {
+			continue
+		}
+
+		localCards := findLocalCards(fsSlug)
+		remoteCards := groupRemoteCards(fsSlug, savesByPlatform[fsSlug])
+
+		anchorRomID := sharedCardAnchor(roms)
+
+		keys := make(map[string]bool)
+		for key := range localCards {
+			keys[key] = true
+		}
+		for key := range remoteCards {
+			keys[key] = true
+		}
+
+		for key := range keys {
+			local := localCards[key]
+			remote := newestSave(remoteCards[key])
+
+			romID := anchorRomID
+			if remote.ID != 0 {
+				romID = remote.RomID
+			}
+			if romID == 0 {
+				logger.Debug("No ROM to attach shared card to", "fsSlug", fsSlug, "card", key)
+				continue
+			}
+
+			fileName := key[strings.Index(key, "/")+1:]
+			action, reason := sharedCardSyncAction(local, remote)
+
+			syncs = append(syncs, SaveSync{
+				RomID:      romID,
+				RomName:    fileName,
+				FSSlug:     fsSlug,
+				GameBase:   strings.TrimSuffix(fileName, filepath.Ext(fileName)),
+				Local:      local,
+				Remote:     remote,
+				Action:     action,
+				Reason:     reason,
+				SharedCard: true,
+			})
+		}
+	}
+
+	return syncs
+}
+
+// findLocalCards returns the platform's shared cards on this device, keyed by sharedCardKey.
+func findLocalCards(fsSlug string) map[string]*LocalSave {
+	cards := make(map[string]*LocalSave)
+	for _, save := range findSaveFiles(fsSlug) {
+		name := filepath.Base(save.Path)
+		if cfw.IsSharedCard(fsSlug, name) {
+			cards[sharedCardKey(filepath.Base(filepath.Dir(save.Path)), name)] = &save
+		}
+	}
+	return cards
+}
+
+// groupRemoteCards picks the shared cards out of a platform's remote saves, keyed by
+// sharedCardKey.
+func groupRemoteCards(fsSlug string, saves []romm.Save) map[string][]romm.Save {
+	cards := make(map[string][]romm.Save)
+	for _, save := range saves {
+		name := untaggedSaveName(save)
+		if cfw.IsSharedCard(fsSlug, name) {
+			key := sharedCardKey(save.Emulator, name)
+			cards[key] = append(cards[key], save)
+		}
+	}
+	return cards
+}
+
+// sharedCardAnchor picks the lowest matched ROM ID on the platform, so devices with the
+// same library attach new cards to the same game.
+func sharedCardAnchor(roms []LocalRomFile) int {
+	anchor := 0
+	for _, rom := range roms {
+		if rom.RomID > 0 && (anchor == 0 || rom.RomID < anchor) {
+			anchor = rom.RomID
+		}
+	}
+	return anchor
+}
+
+func newestSave(saves []romm.Save) romm.Save {
+	if len(saves) == 0 {
+		return romm.Save{}
+	}
+	return slices.MaxFunc(saves, func(a, b romm.Save) int {
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	})
+}
+
+// sharedCardSyncAction is stricter than the per-game logic: a card holds many games, so
+// it is never overwritten on timestamps alone. When both sides changed, or there is no
+// record of a previous sync, the result is a Conflict, unless the local card holds no
+// saves at all.
+func sharedCardSyncAction(local *LocalSave, remote romm.Save) (SyncAction, SyncReason) {
+	switch {
+	case local == nil && remote.ID == 0:
+		return Skip, ReasonNoSaves
+	case local == nil:
+		return Download, ReasonOnlyRemote
+	case remote.ID == 0:
+		return Upload, ReasonOnlyLocal
+	}
+
+	state, found := cache.GetCacheManager().GetSaveSyncState(local.Path)
+	if !found {
+		// An emulator creates a blank card on first launch; there is nothing on it to keep
+		if data, err := os.ReadFile(local.Path); err == nil && blankCard(data) {
+			return Download, ReasonBlankCard
+		}
+		return Conflict, ReasonCardConflict
+	}
+
+	localHash, err := local.hash()
+	if err != nil {
+		return Skip, ReasonUnchanged
+	}
+
+	localChanged := localHash != state.ContentHash
+	remoteChanged := remote.ID != state.RemoteSaveID
+
+	switch {
+	case !localChanged && !remoteChanged:
+		return Skip, ReasonUnchanged
+	case !localChanged:
+		return Download, ReasonRemoteChanged
+	case !remoteChanged:
+		return Upload, ReasonLocalChanged
+	default:
+		return Conflict, ReasonCardConflict
+	}
+}
+
+const (
+	ps1CardSize  = 128 * 1024
+	ps1FrameSize = 128
+	vmuSize      = 128 * 1024
+	vmuBlockSize = 512
+)
+
+// blankCard reports whether a memory card holds no saves: it is empty, filled with a
+// single byte, or a freshly formatted PlayStation or VMU card.
+func blankCard(data []byte) bool {
+	if len(data) == 0 || bytes.Count(data, data[:1]) == len(data) {
+		return true
+	}
+
+	switch {
+	case len(data) == ps1CardSize && bytes.HasPrefix(data, []byte("MC")):
+		// Frames 1-15 describe the card's blocks; 0xA0-0xA3 mark a free or deleted block
+		for frame := 1; frame <= 15; frame++ {
+			if data[frame*ps1FrameSize]&0xF0 != 0xA0 {
+				return false
+			}
+		}
+		return true
+
+	case len(data) == vmuSize:
+		// The root block is the last one, and gives where the directory starts and how
+		// many blocks it spans. The directory is laid out from there towards block 0.
+		root := data[len(data)-vmuBlockSize:]
+		if root[0] != 0x55 {
+			return false
+		}
+		dirStart := int(binary.LittleEndian.Uint16(root[0x4A:]))
+		dirBlocks := int(binary.LittleEndian.Uint16(root[0x4C:]))
+		if dirBlocks == 0 || dirStart >= vmuSize/vmuBlockSize || dirBlocks > dirStart+1 {
+			return false
+		}
+		for block := dirStart; block > dirStart-dirBlocks; block-- {
+			dir := data[block*vmuBlockSize : (block+1)*vmuBlockSize]
+			// Each 32-byte entry starts with its file type; zero means no file
+			for entry := 0; entry < vmuBlockSize; entry += 32 {
+				if dir[entry] != 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// sharedCardDir places a downloaded card in the emulator folder it was uploaded from,
+// falling back to the platform's save folder when that emulator isn't on this CFW.
+func sharedCardDir(fsSlug string, remote romm.Save, config *internal.Config) (string, error) {
+	if slices.Contains(cfw.EmulatorFoldersForFSSlug(fsSlug), remote.Emulator) {
+		dir := filepath.Join(cfw.BaseSavePath(), remote.Emulator)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+		return dir, nil
+	}
+	return ResolveSavePath(fsSlug, 0, config)
+}
+
+// keepConflictCopy resolves a shared card conflict in favour of the local card. The RomM
+// copy is written to a .conflicts folder beside it so nothing is lost, and the sync state
+// is updated so the local card is uploaded the next time it changes.
+func (s *SaveSync) keepConflictCopy(host romm.Host, config *internal.Config) (string, error) {
+	if s.Local == nil {
+		return "", fmt.Errorf("cannot resolve conflict: no local save file")
+	}
+	if config == nil {
+		return "", fmt.Errorf("config is nil")
+	}
+
+	rc := romm.NewClientFromHost(host, config.ApiTimeout)
+	saveData, err := rc.DownloadSave(s.Remote.DownloadPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to download save: %w", err)
+	}
+
+	localHash, err := s.Local.hash()
+	if err != nil {
+		return "", err
+	}
+
+	// Both sides already hold the same card; only the sync record was missing
+	if hashSaveData(saveData) == localHash {
+		recordSyncState(s.Local.Path, s.RomID, localHash, s.Remote)
+		return s.Local.Path, nil
+	}
+
+	ext := filepath.Ext(s.Local.Path)
+	device := SaveDeviceName(s.Remote)
+	if device == "" {
+		device = "RomM"
+	}
+	name := fmt.Sprintf("%s [%s %s]%s", s.GameBase, device, s.Remote.UpdatedAt.Format(backupTimestampFormat), ext)
+	dest := filepath.Join(filepath.Dir(s.Local.Path), ".conflicts", name)
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", err
+	}
+	if err := fileutil.WriteFileAtomic(dest, saveData, 0644); err != nil {
+		return "", fmt.Errorf("failed to write conflict copy: %w", err)
+	}
+	_ = os.Chtimes(dest, time.Now(), s.Remote.UpdatedAt)
+
+	recordSyncState(s.Local.Path, s.RomID, localHash, s.Remote)
+
+	gaba.GetLogger().Info("Kept shared card conflict copy", "card", s.Local.Path, "copy", dest)
+
+	return dest, nil
+}
@@ -0,0 +1,60 @@
+package sync
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func formattedPS1Card() []byte {
+	data := make([]byte, ps1CardSize)
+	copy(data, "MC")
+	for frame := 1; frame <= 15; frame++ {
+		data[frame*ps1FrameSize] = 0xA0
+	}
+	return data
+}
+
+func formattedVMU() []byte {
+	data := make([]byte, vmuSize)
+	root := data[len(data)-vmuBlockSize:]
+	for i := 0; i < 16; i++ {
+		root[i] = 0x55
+	}
+	binary.LittleEndian.PutUint16(root[0x4A:], 253)
+	binary.LittleEndian.PutUint16(root[0x4C:], 13)
+	return data
+}
+
+func TestBlankCard(t *testing.T) {
+	ps1InUse := formattedPS1Card()
+	ps1InUse[2*ps1FrameSize] = 0x51
+
+	ps1Deleted := formattedPS1Card()
+	ps1Deleted[3*ps1FrameSize] = 0xA1
+
+	vmuInUse := formattedVMU()
+	vmuInUse[250*vmuBlockSize+32] = 0x33
+
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"empty", nil, true},
+		{"zero filled", make([]byte, ps1CardSize), true},
+		{"formatted PlayStation card", formattedPS1Card(), true},
+		{"PlayStation card with a deleted save", ps1Deleted, true},
+		{"PlayStation card in use", ps1InUse, false},
+		{"formatted VMU", formattedVMU(), true},
+		{"VMU in use", vmuInUse, false},
+		{"unknown format", []byte("save data"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blankCard(tt.data); got != tt.want {
+				t.Errorf("blankCard() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"grout/cache"
+	"grout/download"
+	"grout/internal/stringutil"
+	"strings"
+	"time"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	icons "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/constants"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// activityLimit is how many history entries the screen lists. The export has them all.
+const activityLimit = 200
+
+type ActivityOutput struct{}
+
+type ActivityScreen struct{}
+
+func NewActivityScreen() *ActivityScreen {
+	return &ActivityScreen{}
+}
+
+// Execute lists what was recently downloaded, extracted, updated and removed, newest
+// first. A shows the details of an entry and X exports the whole history to a CSV file.
+func (s *ActivityScreen) Execute() ActivityOutput {
+	logger := gaba.GetLogger()
+
+	entries, err := cache.GetCacheManager().GetHistory(activityLimit)
+	if err != nil {
+		logger.Error("Unable to load history", "error", err)
+	}
+	if len(entries) == 0 {
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "activity_empty", Other: "Nothing has been downloaded or removed yet."}, nil),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+		return ActivityOutput{}
+	}
+
+	items := make([]gaba.MenuItem, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, gaba.MenuItem{
+			Text:     fmt.Sprintf("%s · %s", e.RomName, activityStatusText(e)),
+			Metadata: e,
+		})
+	}
+
+	selectedIndex := 0
+	visibleStart := 0
+
+	for {
+		options := gaba.DefaultListOptions(
+			i18n.Localize(&goi18n.Message{ID: "activity_title", Other: "Recent Activity"}, nil),
+			items,
+		)
+		options.SmallTitle = true
+		options.ActionButton = icons.VirtualButtonX
+		options.SelectedIndex = selectedIndex
+		options.VisibleStartIndex = visibleStart
+		options.StatusBar = StatusBar()
+		options.FooterHelpItems = []gaba.FooterHelpItem{
+			FooterBack(),
+			footerItem("X", "button_export", "Export"),
+			footerItem("A", "button_details", "Details"),
+		}
+
+		result, err := gaba.List(options)
+		if err != nil {
+			if !errors.Is(err, gaba.ErrCancelled) {
+				logger.Error("Recent activity error", "error", err)
+			}
+			return ActivityOutput{}
+		}
+
+		if len(result.Selected) > 0 {
+			selectedIndex = result.Selected[0]
+			visibleStart = max(0, selectedIndex-result.VisiblePosition)
+		}
+
+		switch result.Action {
+		case gaba.ListActionSelected:
+			if len(result.Selected) == 0 {
+				continue
+			}
+			gaba.ConfirmationMessage(
+				activityDetails(result.Items[selectedIndex].Metadata.(cache.HistoryEntry)),
+				ContinueFooter(),
+				gaba.MessageOptions{},
+			)
+
+		case gaba.ListActionTriggered:
+			s.export()
+		}
+	}
+}
+
+func (s *ActivityScreen) export() {
+	var path string
+	var err error
+	gaba.ProcessMessage(
+		i18n.Localize(&goi18n.Message{ID: "activity_exporting", Other: "Exporting activity..."}, nil),
+		gaba.ProcessMessageOptions{ShowThemeBackground: true},
+		func() (interface{}, error) {
+			path, err = download.ExportHistory()
+			return nil, nil
+		},
+	)
+
+	if err != nil {
+		gaba.GetLogger().Error("Unable to export history", "error", err)
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "activity_export_failed", Other: "Unable to export the activity."}, nil),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+		return
+	}
+
+	gaba.ConfirmationMessage(
+		i18n.Localize(&goi18n.Message{ID: "activity_exported", Other: "Activity exported to:\n{{.Path}}"}, map[string]interface{}{"Path": path}),
+		ContinueFooter(),
+		gaba.MessageOptions{},
+	)
+}
+
+func activityStatusText(e cache.HistoryEntry) string {
+	if e.Error != "" {
+		switch e.Action {
+		case cache.HistoryUpdate:
+			return i18n.Localize(&goi18n.Message{ID: "activity_update_failed", Other: "Update Failed"}, nil)
+		case cache.HistoryExtract:
+			return i18n.Localize(&goi18n.Message{ID: "activity_extract_failed", Other: "Extraction Failed"}, nil)
+		case cache.HistoryUninstall:
+			return i18n.Localize(&goi18n.Message{ID: "activity_uninstall_failed", Other: "Removal Failed"}, nil)
+		default:
+			return i18n.Localize(&goi18n.Message{ID: "activity_download_failed", Other: "Download Failed"}, nil)
+		}
+	}
+
+	switch e.Action {
+	case cache.HistoryUpdate:
+		return i18n.Localize(&goi18n.Message{ID: "activity_updated", Other: "Updated"}, nil)
+	case cache.HistoryExtract:
+		return i18n.Localize(&goi18n.Message{ID: "activity_extracted", Other: "Extracted"}, nil)
+	case cache.HistoryUninstall:
+		return i18n.Localize(&goi18n.Message{ID: "activity_removed", Other: "Removed"}, nil)
+	default:
+		return i18n.Localize(&goi18n.Message{ID: "activity_downloaded", Other: "Downloaded"}, nil)
+	}
+}
+
+func activityDetails(e cache.HistoryEntry) string {
+	platform := e.PlatformName
+	if platform == "" {
+		platform = e.PlatformSlug
+	}
+
+	lines := []string{
+		e.RomName,
+		activityStatusText(e),
+		e.At.Local().Format("2006-01-02 15:04"),
+		i18n.Localize(&goi18n.Message{ID: "activity_platform", Other: "Platform: {{.Platform}}"}, map[string]interface{}{"Platform": platform}),
+		i18n.Localize(&goi18n.Message{ID: "activity_size", Other: "Size: {{.Size}}"}, map[string]interface{}{"Size": stringutil.FormatBytes(e.SizeBytes)}),
+		i18n.Localize(&goi18n.Message{ID: "activity_duration", Other: "Took: {{.Duration}}"}, map[string]interface{}{"Duration": e.Duration.Round(time.Second).String()}),
+	}
+	if e.Host != "" {
+		lines = append(lines, i18n.Localize(&goi18n.Message{ID: "activity_host", Other: "From: {{.Host}}"}, map[string]interface{}{"Host": e.Host}))
+	}
+	if e.Verification != "" {
+		lines = append(lines, i18n.Localize(&goi18n.Message{ID: "activity_verification", Other: "Checksum: {{.Result}}"}, map[string]interface{}{"Result": e.Verification}))
+	}
+	if e.Error != "" {
+		lines = append(lines, e.Error)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -20,10 +20,12 @@ type AdvancedSettingsInput struct {
 }
 
 type AdvancedSettingsOutput struct {
-	RefreshCacheClicked   bool
-	SyncArtworkClicked    bool
-	LastSelectedIndex     int
-	LastVisibleStartIndex int
+	RefreshCacheClicked     bool
+	SyncArtworkClicked      bool
+	RebuildPlaylistsClicked bool
+	VerifyGamesClicked      bool
+	LastSelectedIndex       int
+	LastVisibleStartIndex   int
 }
 
 type AdvancedSettingsScreen struct{}
@@ -79,6 +81,16 @@ func (s *AdvancedSettingsScreen) Draw(input AdvancedSettingsInput) (ScreenResult
 			output.SyncArtworkClicked = true
 			return withCode(output, constants.ExitCodeSyncArtwork), nil
 		}
+
+		if selectedText == i18n.Localize(&goi18n.Message{ID: "settings_rebuild_playlists", Other: "Rebuild Playlists"}, nil) {
+			output.RebuildPlaylistsClicked = true
+			return withCode(output, constants.ExitCodeRebuildPlaylists), nil
+		}
+
+		if selectedText == i18n.Localize(&goi18n.Message{ID: "settings_verify_games", Other: "Verify Games"}, nil) {
+			output.VerifyGamesClicked = true
+			return withCode(output, constants.ExitCodeVerifyGames), nil
+		}
 	}
 
 	s.applySettings(config, result.Items)
@@ -102,6 +114,14 @@ func (s *AdvancedSettingsScreen) buildMenuItems(config *internal.Config) []gaba.
 			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "settings_refresh_cache", Other: "Refresh Cache"}, nil)},
 			Options: []gaba.Option{{Type: gaba.OptionTypeClickable}},
 		},
+		{
+			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "settings_rebuild_playlists", Other: "Rebuild Playlists"}, nil)},
+			Options: []gaba.Option{{Type: gaba.OptionTypeClickable}},
+		},
+		{
+			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "settings_verify_games", Other: "Verify Games"}, nil)},
+			Options: []gaba.Option{{Type: gaba.OptionTypeClickable}},
+		},
 		{
 			Item: gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "settings_download_timeout", Other: "Download Timeout"}, nil)},
 			Options: []gaba.Option{
@@ -294,7 +294,7 @@ func (s *BIOSDownloadScreen) draw(input BIOSDownloadInput) (ScreenResult[BIOSDow
 				continue
 			}
 
-			if err := os.WriteFile(filePath, data, 0644); err != nil {
+			if err := fileutil.WriteFileAtomic(filePath, data, 0644); err != nil {
 				logger.Error("Failed to save BIOS file", "file", info.firmware.FileName, "error", err)
 				continue
 			}
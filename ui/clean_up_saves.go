@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"errors"
+	"grout/internal"
+	"grout/internal/stringutil"
+	"grout/romm"
+	"grout/sync"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"go.uber.org/atomic"
+)
+
+type CleanUpSavesInput struct {
+	Config *internal.Config
+	Host   romm.Host
+}
+
+type CleanUpSavesOutput struct{}
+
+type CleanUpSavesScreen struct{}
+
+func NewCleanUpSavesScreen() *CleanUpSavesScreen {
+	return &CleanUpSavesScreen{}
+}
+
+func (s *CleanUpSavesScreen) Execute(config *internal.Config, host romm.Host) CleanUpSavesOutput {
+	s.draw(CleanUpSavesInput{
+		Config: config,
+		Host:   host,
+	})
+	return CleanUpSavesOutput{}
+}
+
+// draw deletes old saves from RomM, keeping the newest per game and device. Nothing is
+// deleted until the user has seen how many saves and how much space are involved.
+func (s *CleanUpSavesScreen) draw(input CleanUpSavesInput) {
+	logger := gaba.GetLogger()
+
+	keep := input.Config.SaveRetention
+	if keep <= 0 {
+		keep = sync.DefaultSaveRetention
+	}
+
+	var plan sync.SaveCleanupPlan
+	var planErr error
+	gaba.ProcessMessage(
+		i18n.Localize(&goi18n.Message{ID: "clean_up_saves_finding", Other: "Looking for old saves on RomM..."}, nil),
+		gaba.ProcessMessageOptions{ShowThemeBackground: true},
+		func() (interface{}, error) {
+			plan, planErr = sync.PlanServerSaveCleanup(input.Host, input.Config, keep)
+			return nil, nil
+		},
+	)
+
+	if planErr != nil {
+		logger.Error("Unable to plan save clean up", "error", planErr)
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "clean_up_saves_failed", Other: "Unable to fetch saves from RomM."}, nil),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+		return
+	}
+
+	if len(plan.Saves) == 0 {
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "clean_up_saves_none", Other: "There are no old saves to clean up."}, nil),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+		return
+	}
+
+	_, err := gaba.ConfirmationMessage(
+		i18n.Localize(&goi18n.Message{ID: "clean_up_saves_confirm", Other: "Delete {{.Count}} old saves from RomM ({{.Size}})?\nThe newest {{.Keep}} per game and device are kept."},
+			map[string]interface{}{"Count": len(plan.Saves), "Size": stringutil.FormatBytes(plan.Bytes), "Keep": plan.Keep}),
+		[]gaba.FooterHelpItem{
+			FooterCancel(),
+			footerItem("A", "button_delete", "Delete"),
+		},
+		gaba.MessageOptions{},
+	)
+	if err != nil {
+		if !errors.Is(err, gaba.ErrCancelled) {
+			logger.Error("Clean up confirmation error", "error", err)
+		}
+		return
+	}
+
+	var freed int64
+	var deleteErr error
+	progress := &atomic.Float64{}
+	gaba.ProcessMessage(
+		i18n.Localize(&goi18n.Message{ID: "clean_up_saves_deleting", Other: "Deleting old saves..."}, nil),
+		gaba.ProcessMessageOptions{
+			ShowProgressBar: true,
+			Progress:        progress,
+		},
+		func() (interface{}, error) {
+			freed, deleteErr = sync.CleanUpServerSaves(input.Host, input.Config, plan, progress.Store)
+			return nil, nil
+		},
+	)
+
+	message := i18n.Localize(&goi18n.Message{ID: "clean_up_saves_done", Other: "Freed {{.Size}} on RomM."}, map[string]interface{}{"Size": stringutil.FormatBytes(freed)})
+	if deleteErr != nil {
+		logger.Error("Unable to delete old saves", "error", deleteErr)
+		message = i18n.Localize(&goi18n.Message{ID: "clean_up_saves_partial", Other: "Some saves could not be deleted.\nFreed {{.Size}} on RomM."}, map[string]interface{}{"Size": stringutil.FormatBytes(freed)})
+	}
+
+	gaba.ConfirmationMessage(message, ContinueFooter(), gaba.MessageOptions{})
+}
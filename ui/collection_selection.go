@@ -33,6 +33,9 @@ type CollectionSelectionOutput struct {
 
 type CollectionSelectionScreen struct{}
 
+// syncSubscriptionsRow marks the list row that syncs the subscribed collections.
+type syncSubscriptionsRow struct{}
+
 func NewCollectionSelectionScreen() *CollectionSelectionScreen {
 	return &CollectionSelectionScreen{}
 }
@@ -103,10 +106,22 @@ func (s *CollectionSelectionScreen) Draw(input CollectionSelectionInput) (Screen
 		return withCode(output, gaba.ExitCode(404)), nil
 	}
 
+	manageSubscriptions := !internal.IsKidModeEnabled()
+
 	var menuItems []gaba.MenuItem
+	if manageSubscriptions && input.SearchFilter == "" && len(input.Config.Subscriptions) > 0 {
+		menuItems = append(menuItems, gaba.MenuItem{
+			Text:     fmt.Sprintf("%s %s", buttons.CloudDownload, i18n.Localize(&goi18n.Message{ID: "collections_sync_subscriptions", Other: "Sync Subscriptions"}, nil)),
+			Metadata: syncSubscriptionsRow{},
+		})
+	}
 	for _, collection := range displayCollections {
+		text := collection.Name
+		if input.Config.IsSubscribed(collection) {
+			text = buttons.CloudDownload + " " + text
+		}
 		menuItems = append(menuItems, gaba.MenuItem{
-			Text:     collection.Name,
+			Text:     text,
 			Selected: false,
 			Focused:  false,
 			Metadata: collection,
@@ -116,8 +131,11 @@ func (s *CollectionSelectionScreen) Draw(input CollectionSelectionInput) (Screen
 	footerItems := []gaba.FooterHelpItem{
 		{ButtonName: "B", HelpText: i18n.Localize(&goi18n.Message{ID: "button_back", Other: "Back"}, nil)},
 		{ButtonName: "X", HelpText: i18n.Localize(&goi18n.Message{ID: "button_search", Other: "Search"}, nil)},
-		{ButtonName: "A", HelpText: i18n.Localize(&goi18n.Message{ID: "button_select", Other: "Select"}, nil)},
 	}
+	if manageSubscriptions {
+		footerItems = append(footerItems, gaba.FooterHelpItem{ButtonName: "Y", HelpText: i18n.Localize(&goi18n.Message{ID: "button_subscribe", Other: "Subscribe"}, nil)})
+	}
+	footerItems = append(footerItems, gaba.FooterHelpItem{ButtonName: "A", HelpText: i18n.Localize(&goi18n.Message{ID: "button_select", Other: "Select"}, nil)})
 
 	title := "Collections"
 	if input.SearchFilter != "" {
@@ -126,6 +144,9 @@ func (s *CollectionSelectionScreen) Draw(input CollectionSelectionInput) (Screen
 
 	options := gaba.DefaultListOptions(title, menuItems)
 	options.ActionButton = buttons.VirtualButtonX
+	if manageSubscriptions {
+		options.SecondaryActionButton = buttons.VirtualButtonY
+	}
 	options.FooterHelpItems = footerItems
 	options.SelectedIndex = input.LastSelectedIndex
 	options.VisibleStartIndex = max(0, input.LastSelectedIndex-input.LastSelectedPosition)
@@ -147,16 +168,30 @@ func (s *CollectionSelectionScreen) Draw(input CollectionSelectionInput) (Screen
 
 	switch sel.Action {
 	case gaba.ListActionSelected:
-		collection := sel.Items[sel.Selected[0]].Metadata.(romm.Collection)
-
-		output.SelectedCollection = collection
 		output.LastSelectedIndex = sel.Selected[0]
 		output.LastSelectedPosition = sel.VisiblePosition
+
+		collection, ok := sel.Items[sel.Selected[0]].Metadata.(romm.Collection)
+		if !ok {
+			return withCode(output, constants.ExitCodeSyncSubscriptions), nil
+		}
+		output.SelectedCollection = collection
 		return success(output), nil
 
 	case gaba.ListActionTriggered:
 		return withCode(output, constants.ExitCodeSearch), nil
 
+	case gaba.ListActionSecondaryTriggered:
+		output.LastSelectedIndex = sel.Selected[0]
+		output.LastSelectedPosition = sel.VisiblePosition
+
+		collection, ok := sel.Items[sel.Selected[0]].Metadata.(romm.Collection)
+		if !ok {
+			return withCode(output, constants.ExitCodeSyncSubscriptions), nil
+		}
+		output.SelectedCollection = collection
+		return withCode(output, constants.ExitCodeToggleSubscription), nil
+
 	default:
 		return withCode(output, gaba.ExitCodeBack), nil
 	}
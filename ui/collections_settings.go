@@ -3,6 +3,8 @@ package ui
 import (
 	"errors"
 	"grout/internal"
+	"grout/internal/stringutil"
+	"slices"
 
 	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
 	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
@@ -17,6 +19,10 @@ type CollectionsSettingsOutput struct{}
 
 type CollectionsSettingsScreen struct{}
 
+// subscriptionStorageCaps are the limits offered for games downloaded by subscriptions,
+// in bytes.
+var subscriptionStorageCaps = []int64{0, 2 << 30, 4 << 30, 8 << 30, 16 << 30, 32 << 30, 64 << 30}
+
 func NewCollectionsSettingsScreen() *CollectionsSettingsScreen {
 	return &CollectionsSettingsScreen{}
 }
@@ -95,9 +101,26 @@ func (s *CollectionsSettingsScreen) buildMenuItems(config *internal.Config) []ga
 			},
 			SelectedOption: collectionViewToIndex(config.CollectionView),
 		},
+		{
+			Item:           gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "settings_subscription_storage", Other: "Subscription Storage"}, nil)},
+			Options:        s.storageCapOptions(),
+			SelectedOption: max(0, slices.Index(subscriptionStorageCaps, config.SubscriptionStorageCap)),
+		},
 	}
 }
 
+func (s *CollectionsSettingsScreen) storageCapOptions() []gaba.Option {
+	options := make([]gaba.Option, 0, len(subscriptionStorageCaps))
+	for _, limit := range subscriptionStorageCaps {
+		name := i18n.Localize(&goi18n.Message{ID: "download_all_no_limit", Other: "No Limit"}, nil)
+		if limit > 0 {
+			name = stringutil.FormatBytes(limit)
+		}
+		options = append(options, gaba.Option{DisplayName: name, Value: limit})
+	}
+	return options
+}
+
 func (s *CollectionsSettingsScreen) applySettings(config *internal.Config, items []gaba.ItemWithOptions) {
 	for _, item := range items {
 		selectedText := item.Item.Text
@@ -122,6 +145,11 @@ func (s *CollectionsSettingsScreen) applySettings(config *internal.Config, items
 			if val, ok := item.Options[item.SelectedOption].Value.(string); ok {
 				config.CollectionView = val
 			}
+
+		case i18n.Localize(&goi18n.Message{ID: "settings_subscription_storage", Other: "Subscription Storage"}, nil):
+			if val, ok := item.Options[item.SelectedOption].Value.(int64); ok {
+				config.SubscriptionStorageCap = val
+			}
 		}
 	}
 }
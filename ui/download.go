@@ -3,22 +3,17 @@ package ui
 import (
 	"errors"
 	"fmt"
-	"grout/cfw"
-	"grout/cfw/muos"
+	"grout/cache"
+	"grout/download"
 	"grout/internal"
 	"grout/internal/fileutil"
-	"grout/internal/imageutil"
 	"grout/romm"
 	_ "image/gif"
 	_ "image/jpeg"
-	"io"
-	"net/http"
-	"net/url"
-	"os"
 	"path/filepath"
 	"slices"
-	"strconv"
 	"strings"
+	"time"
 
 	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
 	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
@@ -44,12 +39,6 @@ type downloadOutput struct {
 
 type DownloadScreen struct{}
 
-type artDownload struct {
-	URL      string
-	Location string
-	GameName string
-}
-
 func NewDownloadScreen() *DownloadScreen {
 	return &DownloadScreen{}
 }
@@ -89,7 +78,36 @@ func (s *DownloadScreen) draw(input downloadInput) (ScreenResult[downloadOutput]
 		SearchFilter: input.SearchFilter,
 	}
 
-	downloads, artDownloads := s.buildDownloads(input.Config, input.Host, input.Platform, input.SelectedGames)
+	planned := make([]cache.QueuedDownload, 0, len(input.SelectedGames))
+	for _, g := range input.SelectedGames {
+		planned = append(planned, download.PlanRom(input.Config, input.Host, input.Platform, g))
+	}
+
+	planned, ok := NewStorageCheckScreen().Execute(planned, func(selected []cache.QueuedDownload) []download.SpaceShortfall {
+		return download.CheckDirectSpace(input.Config, selected)
+	})
+	if !ok {
+		return back(output), nil
+	}
+
+	downloads := make([]gaba.Download, 0, len(planned))
+	staged := make(map[string]string, len(planned))
+	started := time.Now()
+	for i, d := range planned {
+		planned[i].Started = started
+		location := d.Location
+		if d.Rom.HasMultipleFiles {
+			location = filepath.Join(fileutil.TempDir(), fmt.Sprintf("grout_multirom_%d.zip", d.Rom.ID))
+		}
+		staged[d.Rom.Name] = location
+
+		downloads = append(downloads, gaba.Download{
+			URL:         d.URL,
+			Location:    location,
+			DisplayName: d.Rom.Name,
+			Timeout:     input.Config.DownloadTimeout,
+		})
+	}
 
 	headers := make(map[string]string)
 	headers["Authorization"] = input.Host.BasicAuthHeader()
@@ -121,6 +139,9 @@ func (s *DownloadScreen) draw(input downloadInput) (ScreenResult[downloadOutput]
 	if len(res.Failed) > 0 {
 		for _, f := range res.Failed {
 			logger.Warn("Download failed", "name", f.Download.DisplayName, "url", f.Download.URL, "error", f.Error)
+			if i := slices.IndexFunc(planned, func(d cache.QueuedDownload) bool { return d.Rom.Name == f.Download.DisplayName }); i >= 0 {
+				download.RecordFailedDownload(planned[i], f.Error)
+			}
 		}
 
 		for _, g := range downloads {
@@ -137,140 +158,63 @@ func (s *DownloadScreen) draw(input downloadInput) (ScreenResult[downloadOutput]
 		return withCode(output, gaba.ExitCodeError), nil
 	}
 
-	for _, g := range input.SelectedGames {
-		if !g.HasMultipleFiles {
-			continue
-		}
-
-		completed := slices.ContainsFunc(res.Completed, func(d gaba.Download) bool {
-			return d.DisplayName == g.Name
+	var downloadedGames []romm.Rom
+	var damaged []string
+	var artDownloads []cache.QueuedDownload
+	for _, d := range planned {
+		completed := slices.ContainsFunc(res.Completed, func(c gaba.Download) bool {
+			return c.DisplayName == d.Rom.Name
 		})
 		if !completed {
 			continue
 		}
 
-		gamePlatform := input.Platform
-		if input.Platform.ID == 0 && g.PlatformID != 0 {
-			gamePlatform = romm.Platform{
-				ID:     g.PlatformID,
-				FSSlug: g.PlatformFSSlug,
-				Name:   g.PlatformDisplayName,
-			}
+		message := i18n.Localize(&goi18n.Message{ID: "download_verifying", Other: "Verifying {{.Name}}..."}, map[string]interface{}{"Name": d.Rom.DisplayName})
+		if download.NeedsExtraction(input.Config, d) {
+			message = i18n.Localize(&goi18n.Message{ID: "download_extracting", Other: "Extracting {{.Name}}..."}, map[string]interface{}{"Name": d.Rom.DisplayName})
 		}
 
-		tmpZipPath := filepath.Join(fileutil.TempDir(), fmt.Sprintf("grout_multirom_%d.zip", g.ID))
-		romDirectory := input.Config.GetPlatformRomDirectory(gamePlatform)
-		extractDir := filepath.Join(romDirectory, g.FsNameNoExt)
-
+		var installErr error
 		progress := &atomic.Float64{}
-		_, err := gaba.ProcessMessage(
-			i18n.Localize(&goi18n.Message{ID: "download_extracting", Other: "Extracting {{.Name}}..."}, map[string]interface{}{"Name": g.DisplayName}),
+		gaba.ProcessMessage(
+			message,
 			gaba.ProcessMessageOptions{
 				ShowThemeBackground: true,
-				ShowProgressBar:     true,
+				ShowProgressBar:     download.NeedsExtraction(input.Config, d),
 				Progress:            progress,
 			},
 			func() (interface{}, error) {
-				logger.Debug("Extracting multi-file ROM", "game", g.DisplayName, "dest", extractDir)
-
-				if err := fileutil.Unzip(tmpZipPath, extractDir, progress); err != nil {
-					logger.Error("Failed to extract multi-file ROM", "game", g.DisplayName, "error", err)
-					os.Remove(tmpZipPath)
-					return nil, err
-				}
-
-				if cfw.GetCFW() == cfw.MuOS {
-					if err := muos.OrganizeMultiFileRom(extractDir, romDirectory, g.FsNameNoExt); err != nil {
-						logger.Error("Failed to organize multi-file ROM for muOS", "game", g.FsNameNoExt, "error", err)
-						os.Remove(tmpZipPath)
-						os.RemoveAll(extractDir)
-						return nil, err
-					}
-				}
-
-				if err := os.Remove(tmpZipPath); err != nil {
-					logger.Warn("Failed to remove temp zip file", "path", tmpZipPath, "error", err)
-				}
-
+				installErr = download.Install(input.Config, d, staged[d.Rom.Name], progress)
 				return nil, nil
 			},
 		)
 
-		if err != nil {
-			continue
-		}
-	}
-
-	if input.Config.UnzipDownloads {
-		for _, g := range input.SelectedGames {
-			if g.HasMultipleFiles {
-				continue
-			}
-
-			completed := slices.ContainsFunc(res.Completed, func(d gaba.Download) bool {
-				return d.DisplayName == g.Name
-			})
-			if !completed {
-				continue
-			}
-
-			gamePlatform := input.Platform
-			if input.Platform.ID == 0 && g.PlatformID != 0 {
-				gamePlatform = romm.Platform{
-					ID:     g.PlatformID,
-					FSSlug: g.PlatformFSSlug,
-					Name:   g.PlatformDisplayName,
-				}
-			}
-
-			if len(g.Files) > 0 && strings.ToLower(filepath.Ext(g.Files[0].FileName)) == ".zip" {
-				romDirectory := input.Config.GetPlatformRomDirectory(gamePlatform)
-				zipPath := filepath.Join(romDirectory, g.Files[0].FileName)
-
-				progress := &atomic.Float64{}
-				_, err := gaba.ProcessMessage(
-					i18n.Localize(&goi18n.Message{ID: "download_extracting", Other: "Extracting {{.Name}}..."}, map[string]interface{}{"Name": g.Name}),
-					gaba.ProcessMessageOptions{
-						ShowThemeBackground: true,
-						ShowProgressBar:     true,
-						Progress:            progress,
-					},
-					func() (interface{}, error) {
-						logger.Debug("Extracting single-file ROM", "game", g.Name, "file", zipPath)
-
-						if err := fileutil.Unzip(zipPath, romDirectory, progress); err != nil {
-							logger.Error("Failed to extract single-file ROM", "game", g.Name, "error", err)
-							return nil, err
-						}
-
-						if err := os.Remove(zipPath); err != nil {
-							logger.Warn("Failed to remove zip file after extraction", "path", zipPath, "error", err)
-						}
-
-						return nil, nil
-					},
-				)
-
-				if err != nil {
-					logger.Warn("Failed to extract ROM, keeping zip file", "game", g.Name)
-					continue
-				}
+		if installErr != nil {
+			logger.Error("Failed to install ROM", "game", d.Rom.Name, "error", installErr)
+			if errors.Is(installErr, download.ErrChecksumMismatch) {
+				damaged = append(damaged, d.Rom.Name)
 			}
+			continue
 		}
-	}
 
-	downloadedGames := make([]romm.Rom, 0, len(res.Completed))
-	for _, g := range input.SelectedGames {
-		if slices.ContainsFunc(res.Completed, func(d gaba.Download) bool {
-			return d.DisplayName == g.Name
-		}) {
-			downloadedGames = append(downloadedGames, g)
+		downloadedGames = append(downloadedGames, d.Rom)
+		if d.ArtURL != "" {
+			artDownloads = append(artDownloads, d)
 		}
 	}
 
 	logger.Debug("Download complete", "successful", len(downloadedGames), "attempted", len(input.SelectedGames))
 
-	if len(artDownloads) > 0 && len(downloadedGames) > 0 {
+	if len(damaged) > 0 {
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "download_checksum_failed", Other: "These downloads were damaged and have been removed. Please try again:\n{{.Games}}"},
+				map[string]interface{}{"Games": strings.Join(damaged, "\n")}),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+	}
+
+	if len(artDownloads) > 0 {
 		progress := &atomic.Float64{}
 		_, err := gaba.ProcessMessage(
 			i18n.Localize(&goi18n.Message{ID: "download_artwork", Other: "Downloading artwork..."}, nil),
@@ -280,7 +224,12 @@ func (s *DownloadScreen) draw(input downloadInput) (ScreenResult[downloadOutput]
 				Progress:            progress,
 			},
 			func() (interface{}, error) {
-				s.downloadArt(artDownloads, downloadedGames, headers, progress)
+				for i, d := range artDownloads {
+					if err := download.FetchArt(d.ArtURL, d.ArtLocation, headers); err != nil {
+						logger.Warn("Failed to download art", "game", d.Rom.Name, "url", d.ArtURL, "error", err)
+					}
+					progress.Store(float64(i+1) / float64(len(artDownloads)))
+				}
 				return nil, nil
 			},
 		)
@@ -293,187 +242,3 @@ func (s *DownloadScreen) draw(input downloadInput) (ScreenResult[downloadOutput]
 	output.DownloadedGames = downloadedGames
 	return success(output), nil
 }
-
-func (s *DownloadScreen) buildDownloads(config internal.Config, host romm.Host, platform romm.Platform, games []romm.Rom) ([]gaba.Download, []artDownload) {
-	downloads := make([]gaba.Download, 0, len(games))
-	artDownloads := make([]artDownload, 0, len(games))
-
-	for _, g := range games {
-		gamePlatform := platform
-		if platform.ID == 0 && g.PlatformID != 0 {
-			gamePlatform = romm.Platform{
-				ID:     g.PlatformID,
-				FSSlug: g.PlatformFSSlug,
-				Name:   g.PlatformDisplayName,
-			}
-		}
-
-		romDirectory := config.GetPlatformRomDirectory(gamePlatform)
-		downloadLocation := ""
-
-		sourceURL := ""
-
-		if g.HasMultipleFiles {
-			tmpDir := fileutil.TempDir()
-			downloadLocation = filepath.Join(tmpDir, fmt.Sprintf("grout_multirom_%d.zip", g.ID))
-			sourceURL, _ = url.JoinPath(host.URL(), "/api/roms/", strconv.Itoa(g.ID), "content", g.FsName)
-		} else {
-			downloadLocation = filepath.Join(romDirectory, g.Files[0].FileName)
-			sourceURL, _ = url.JoinPath(host.URL(), "/api/roms/", strconv.Itoa(g.ID), "content", g.Files[0].FileName)
-		}
-
-		downloads = append(downloads, gaba.Download{
-			URL:         sourceURL,
-			Location:    downloadLocation,
-			DisplayName: g.Name,
-			Timeout:     config.DownloadTimeout,
-		})
-
-		if config.DownloadArt && (g.PathCoverLarge != "" || g.PathCoverSmall != "" || g.URLCover != "") {
-			artDir := config.GetArtDirectory(gamePlatform)
-			artFileName := g.FsNameNoExt + ".png"
-			artLocation := filepath.Join(artDir, artFileName)
-
-			var coverPath string
-			if g.PathCoverSmall != "" {
-				coverPath = g.PathCoverSmall
-			} else if g.PathCoverLarge != "" {
-				coverPath = g.PathCoverLarge
-			} else if g.URLCover != "" {
-				coverPath = g.URLCover
-			}
-
-			baseURL := host.URL() + coverPath
-			artURL := strings.ReplaceAll(baseURL, " ", "%20")
-
-			artDownloads = append(artDownloads, artDownload{
-				URL:      artURL,
-				Location: artLocation,
-				GameName: g.Name,
-			})
-		}
-	}
-
-	return downloads, artDownloads
-}
-
-func (s *DownloadScreen) downloadArt(artDownloads []artDownload, downloadedGames []romm.Rom, headers map[string]string, progress *atomic.Float64) {
-	logger := gaba.GetLogger()
-
-	downloadedGameNames := make(map[string]bool)
-	for _, g := range downloadedGames {
-		downloadedGameNames[g.Name] = true
-	}
-
-	totalArt := 0
-	for _, art := range artDownloads {
-		if downloadedGameNames[art.GameName] {
-			totalArt++
-		}
-	}
-
-	successCount := 0
-	failCount := 0
-	processedCount := 0
-
-	for _, art := range artDownloads {
-		if !downloadedGameNames[art.GameName] {
-			continue
-		}
-
-		artDir := filepath.Dir(art.Location)
-		if err := os.MkdirAll(artDir, 0755); err != nil {
-			logger.Warn("Failed to create art directory", "dir", artDir, "game", art.GameName, "error", err)
-			failCount++
-			processedCount++
-			if totalArt > 0 {
-				progress.Store(float64(processedCount) / float64(totalArt))
-			}
-			continue
-		}
-
-		req, err := http.NewRequest("GET", art.URL, nil)
-		if err != nil {
-			logger.Warn("Failed to create art request", "game", art.GameName, "error", err)
-			failCount++
-			processedCount++
-			if totalArt > 0 {
-				progress.Store(float64(processedCount) / float64(totalArt))
-			}
-			continue
-		}
-
-		for k, v := range headers {
-			req.Header.Set(k, v)
-		}
-
-		client := &http.Client{Timeout: romm.DefaultClientTimeout}
-		resp, err := client.Do(req)
-		if err != nil {
-			logger.Warn("Failed to download art", "game", art.GameName, "url", art.URL, "error", err)
-			failCount++
-			processedCount++
-			if totalArt > 0 {
-				progress.Store(float64(processedCount) / float64(totalArt))
-			}
-			continue
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			resp.Body.Close()
-			logger.Warn("Art download failed with bad status", "game", art.GameName, "url", art.URL, "status", resp.Status)
-			failCount++
-			processedCount++
-			if totalArt > 0 {
-				progress.Store(float64(processedCount) / float64(totalArt))
-			}
-			continue
-		}
-
-		outFile, err := os.Create(art.Location)
-		if err != nil {
-			resp.Body.Close()
-			logger.Warn("Failed to create art file", "game", art.GameName, "location", art.Location, "error", err)
-			failCount++
-			processedCount++
-			if totalArt > 0 {
-				progress.Store(float64(processedCount) / float64(totalArt))
-			}
-			continue
-		}
-
-		_, err = io.Copy(outFile, resp.Body)
-		resp.Body.Close()
-		outFile.Close()
-
-		if err != nil {
-			logger.Warn("Failed to write art file", "game", art.GameName, "location", art.Location, "error", err)
-			os.Remove(art.Location)
-			failCount++
-			processedCount++
-			if totalArt > 0 {
-				progress.Store(float64(processedCount) / float64(totalArt))
-			}
-			continue
-		}
-
-		if err := imageutil.ProcessArtImage(art.Location); err != nil {
-			logger.Warn("Failed to process art image", "game", art.GameName, "location", art.Location, "error", err)
-			os.Remove(art.Location)
-			failCount++
-			processedCount++
-			if totalArt > 0 {
-				progress.Store(float64(processedCount) / float64(totalArt))
-			}
-			continue
-		}
-
-		successCount++
-
-		processedCount++
-		if totalArt > 0 {
-			progress.Store(float64(processedCount) / float64(totalArt))
-		}
-	}
-
-}
@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"errors"
+	"grout/download"
+	"grout/internal"
+	"grout/internal/stringutil"
+	"grout/romm"
+	"slices"
+	"strings"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	icons "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/constants"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// downloadAllSizeLimits are the size caps offered, in bytes.
+var downloadAllSizeLimits = []int64{0, 100 << 20, 500 << 20, 1 << 30, 4 << 30}
+
+type DownloadAllScreen struct{}
+
+func NewDownloadAllScreen() *DownloadAllScreen {
+	return &DownloadAllScreen{}
+}
+
+// Execute picks which of a platform's or collection's games to download, and returns
+// them once the user has confirmed a summary.
+func (s *DownloadAllScreen) Execute(config *internal.Config, platform romm.Platform, games []romm.Rom) ([]romm.Rom, bool) {
+	logger := gaba.GetLogger()
+
+	// Collections can hold games for platforms that aren't set up on this device
+	games = slices.DeleteFunc(slices.Clone(games), func(game romm.Rom) bool {
+		_, mapped := config.DirectoryMappings[game.PlatformFSSlug]
+		return !mapped
+	})
+
+	filter, ok := s.chooseFilter(config, games)
+	if !ok {
+		return nil, false
+	}
+
+	var selection download.BulkSelection
+	gaba.ProcessMessage(
+		i18n.Localize(&goi18n.Message{ID: "download_all_selecting", Other: "Choosing games..."}, nil),
+		gaba.ProcessMessageOptions{ShowThemeBackground: true},
+		func() (interface{}, error) {
+			selection = download.SelectBulk(*config, platform, games, filter)
+			return nil, nil
+		},
+	)
+
+	logger.Debug("Download all selection", "games", len(selection.Games), "size", selection.Size,
+		"downloaded", selection.Downloaded, "otherVersions", selection.OtherVersions,
+		"tooLarge", selection.TooLarge, "otherGenre", selection.OtherGenre)
+
+	if len(selection.Games) == 0 {
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "download_all_none", Other: "No games match these filters."}, nil),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+		return nil, false
+	}
+
+	_, err := gaba.ConfirmationMessage(
+		s.summary(selection),
+		[]gaba.FooterHelpItem{
+			FooterCancel(),
+			FooterDownload(),
+		},
+		gaba.MessageOptions{},
+	)
+	if err != nil {
+		if !errors.Is(err, gaba.ErrCancelled) {
+			logger.Error("Download all confirmation error", "error", err)
+		}
+		return nil, false
+	}
+
+	return selection.Games, true
+}
+
+func (s *DownloadAllScreen) chooseFilter(config *internal.Config, games []romm.Rom) (download.BulkFilter, bool) {
+	onOff := func() []gaba.Option {
+		return []gaba.Option{
+			{DisplayName: i18n.Localize(&goi18n.Message{ID: "common_on", Other: "On"}, nil), Value: true},
+			{DisplayName: i18n.Localize(&goi18n.Message{ID: "common_off", Other: "Off"}, nil), Value: false},
+		}
+	}
+
+	regionOptions := make([]gaba.Option, 0)
+	for _, region := range s.regions(config, games) {
+		regionOptions = append(regionOptions, gaba.Option{DisplayName: region, Value: region})
+	}
+
+	sizeOptions := make([]gaba.Option, 0, len(downloadAllSizeLimits))
+	for _, limit := range downloadAllSizeLimits {
+		name := i18n.Localize(&goi18n.Message{ID: "download_all_no_limit", Other: "No Limit"}, nil)
+		if limit > 0 {
+			name = stringutil.FormatBytes(limit)
+		}
+		sizeOptions = append(sizeOptions, gaba.Option{DisplayName: name, Value: limit})
+	}
+
+	genreOptions := []gaba.Option{{DisplayName: i18n.Localize(&goi18n.Message{ID: "download_all_any_genre", Other: "Any"}, nil), Value: ""}}
+	for _, genre := range s.genres(games) {
+		genreOptions = append(genreOptions, gaba.Option{DisplayName: genre, Value: genre})
+	}
+
+	items := []gaba.ItemWithOptions{
+		{
+			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "download_all_not_downloaded", Other: "Skip Downloaded Games"}, nil)},
+			Options: onOff(),
+		},
+		{
+			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "download_all_one_version", Other: "One Version Per Game"}, nil)},
+			Options: onOff(),
+		},
+		{
+			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "download_all_region", Other: "Preferred Region"}, nil)},
+			Options: regionOptions,
+		},
+		{
+			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "download_all_max_size", Other: "Max Game Size"}, nil)},
+			Options: sizeOptions,
+		},
+		{
+			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "download_all_genre", Other: "Genre"}, nil)},
+			Options: genreOptions,
+		},
+	}
+
+	result, err := gaba.OptionsList(
+		i18n.Localize(&goi18n.Message{ID: "download_all_title", Other: "Download All"}, nil),
+		gaba.OptionListSettings{
+			FooterHelpItems: []gaba.FooterHelpItem{
+				FooterCancel(),
+				FooterCycle(),
+				footerItem(icons.Start, "button_next", "Next"),
+			},
+			StatusBar:  StatusBar(),
+			SmallTitle: true,
+		},
+		items,
+	)
+	if err != nil {
+		if !errors.Is(err, gaba.ErrCancelled) {
+			gaba.GetLogger().Error("Download all options error", "error", err)
+		}
+		return download.BulkFilter{}, false
+	}
+
+	value := func(i int) interface{} {
+		item := result.Items[i]
+		return item.Options[item.SelectedOption].Value
+	}
+
+	filter := download.BulkFilter{
+		NotDownloaded: value(0).(bool),
+		OneVersion:    value(1).(bool),
+		MaxSize:       value(3).(int64),
+		Genre:         value(4).(string),
+		Language:      config.Language,
+	}
+
+	if region := value(2).(string); region != config.Regions()[0] {
+		config.SetPreferredRegion(region)
+		if err := internal.SaveConfig(config); err != nil {
+			gaba.GetLogger().Error("Error saving preferred region", "error", err)
+		}
+	}
+	filter.Regions = config.Regions()
+
+	return filter, true
+}
+
+// regions lists the region priority followed by any other regions the games have.
+func (s *DownloadAllScreen) regions(config *internal.Config, games []romm.Rom) []string {
+	regions := slices.Clone(config.Regions())
+	var others []string
+	for _, game := range games {
+		for _, region := range game.Regions {
+			known := func(r string) bool { return strings.EqualFold(r, region) }
+			if !slices.ContainsFunc(regions, known) && !slices.ContainsFunc(others, known) {
+				others = append(others, region)
+			}
+		}
+	}
+	slices.Sort(others)
+	return append(regions, others...)
+}
+
+func (s *DownloadAllScreen) genres(games []romm.Rom) []string {
+	var genres []string
+	for _, game := range games {
+		for _, genre := range game.Metadatum.Genres {
+			if !slices.Contains(genres, genre) {
+				genres = append(genres, genre)
+			}
+		}
+	}
+	slices.Sort(genres)
+	return genres
+}
+
+func (s *DownloadAllScreen) summary(selection download.BulkSelection) string {
+	lines := []string{i18n.Localize(&goi18n.Message{ID: "download_all_summary", Other: "Download {{.Count}} games ({{.Size}})?"},
+		map[string]interface{}{"Count": len(selection.Games), "Size": stringutil.FormatBytes(selection.Size)})}
+
+	skipped := []struct {
+		count int
+		msg   *goi18n.Message
+	}{
+		{selection.Downloaded, &goi18n.Message{ID: "download_all_skipped_downloaded", Other: "{{.Count}} already downloaded"}},
+		{selection.OtherVersions, &goi18n.Message{ID: "download_all_skipped_versions", Other: "{{.Count}} other versions skipped"}},
+		{selection.TooLarge, &goi18n.Message{ID: "download_all_skipped_size", Other: "{{.Count}} over the size limit"}},
+		{selection.OtherGenre, &goi18n.Message{ID: "download_all_skipped_genre", Other: "{{.Count}} in other genres"}},
+	}
+	for _, skip := range skipped {
+		if skip.count > 0 {
+			lines = append(lines, i18n.Localize(skip.msg, map[string]interface{}{"Count": skip.count}))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"grout/cache"
+	"grout/download"
+	"slices"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	icons "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/constants"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type DownloadQueueOutput struct{}
+
+type DownloadQueueScreen struct{}
+
+func NewDownloadQueueScreen() *DownloadQueueScreen {
+	return &DownloadQueueScreen{}
+}
+
+// Execute shows the background download queue until the user backs out. A pauses or
+// resumes the focused download, X cancels it and Select reorders the queue.
+func (s *DownloadQueueScreen) Execute(queue *download.Queue) DownloadQueueOutput {
+	if queue == nil {
+		return DownloadQueueOutput{}
+	}
+
+	selectedIndex := 0
+	visibleStart := 0
+
+	for {
+		queued := queue.Items()
+		if len(queued) == 0 {
+			gaba.ConfirmationMessage(
+				i18n.Localize(&goi18n.Message{ID: "download_queue_empty", Other: "There are no downloads in the queue."}, nil),
+				ContinueFooter(),
+				gaba.MessageOptions{},
+			)
+			return DownloadQueueOutput{}
+		}
+
+		items := make([]gaba.MenuItem, 0, len(queued))
+		for _, d := range queued {
+			items = append(items, gaba.MenuItem{
+				Text:     fmt.Sprintf("%s · %s", d.Rom.Name, queueStatusText(d)),
+				Metadata: d,
+			})
+		}
+
+		options := gaba.DefaultListOptions(
+			i18n.Localize(&goi18n.Message{ID: "download_queue_title", Other: "Download Queue"}, nil),
+			items,
+		)
+		options.SmallTitle = true
+		options.ActionButton = icons.VirtualButtonX
+		options.ReorderButton = icons.VirtualButtonSelect
+		options.SelectedIndex = min(selectedIndex, len(items)-1)
+		options.VisibleStartIndex = visibleStart
+		options.StatusBar = StatusBar()
+		options.FooterHelpItems = []gaba.FooterHelpItem{
+			FooterBack(),
+			footerItem("X", "button_cancel_download", "Cancel Download"),
+			footerItem(icons.Select, "button_reorder", "Reorder"),
+			footerItem("A", "button_pause_resume", "Pause / Resume"),
+		}
+
+		result, err := gaba.List(options)
+
+		if result != nil && len(result.Items) == len(queued) {
+			s.saveOrder(queue, queued, result.Items)
+		}
+
+		if err != nil {
+			if !errors.Is(err, gaba.ErrCancelled) {
+				gaba.GetLogger().Error("Download queue error", "error", err)
+			}
+			return DownloadQueueOutput{}
+		}
+
+		if len(result.Selected) == 0 {
+			continue
+		}
+
+		selectedIndex = result.Selected[0]
+		visibleStart = max(0, selectedIndex-result.VisiblePosition)
+		d := result.Items[selectedIndex].Metadata.(cache.QueuedDownload)
+
+		switch result.Action {
+		case gaba.ListActionSelected:
+			switch d.State {
+			case cache.DownloadPaused, cache.DownloadFailed:
+				queue.Resume(d.ID)
+			default:
+				queue.Pause(d.ID)
+			}
+
+		case gaba.ListActionTriggered:
+			_, err := gaba.ConfirmationMessage(
+				i18n.Localize(&goi18n.Message{ID: "download_queue_cancel_confirm", Other: "Cancel the download of {{.Name}}?"}, map[string]interface{}{"Name": d.Rom.Name}),
+				[]gaba.FooterHelpItem{
+					FooterBack(),
+					FooterConfirm(),
+				},
+				gaba.MessageOptions{},
+			)
+			if err == nil {
+				queue.Cancel(d.ID)
+			}
+		}
+	}
+}
+
+func (s *DownloadQueueScreen) saveOrder(queue *download.Queue, before []cache.QueuedDownload, after []gaba.MenuItem) {
+	ids := make([]int64, 0, len(after))
+	for _, item := range after {
+		ids = append(ids, item.Metadata.(cache.QueuedDownload).ID)
+	}
+
+	unchanged := slices.EqualFunc(before, ids, func(d cache.QueuedDownload, id int64) bool {
+		return d.ID == id
+	})
+	if !unchanged {
+		queue.Reorder(ids)
+	}
+}
+
+func queueStatusText(d cache.QueuedDownload) string {
+	switch d.State {
+	case cache.DownloadActive:
+		if d.BytesTotal > 0 {
+			return i18n.Localize(&goi18n.Message{ID: "download_queue_downloading", Other: "Downloading {{.Percent}}%"},
+				map[string]interface{}{"Percent": d.BytesDone * 100 / d.BytesTotal})
+		}
+		return i18n.Localize(&goi18n.Message{ID: "download_queue_downloading_unknown", Other: "Downloading"}, nil)
+	case cache.DownloadPaused:
+		return i18n.Localize(&goi18n.Message{ID: "download_queue_paused", Other: "Paused"}, nil)
+	case cache.DownloadFailed:
+		if d.Failure == cache.FailureDamaged {
+			return i18n.Localize(&goi18n.Message{ID: "download_queue_damaged", Other: "Damaged"}, nil)
+		}
+		return i18n.Localize(&goi18n.Message{ID: "download_queue_failed", Other: "Failed"}, nil)
+	default:
+		return i18n.Localize(&goi18n.Message{ID: "download_queue_queued", Other: "Queued"}, nil)
+	}
+}
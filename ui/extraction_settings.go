@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"errors"
+	"grout/cache"
+	"grout/internal"
+	"slices"
+	"sort"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type ExtractionSettingsInput struct {
+	Config *internal.Config
+}
+
+type ExtractionSettingsOutput struct {
+	Config *internal.Config
+}
+
+// ExtractionSettingsScreen chooses, per platform, whether archived downloads are
+// extracted. Platforms that always keep their archives aren't listed.
+type ExtractionSettingsScreen struct {
+	displayToFSSlug map[string]string
+}
+
+func NewExtractionSettingsScreen() *ExtractionSettingsScreen {
+	return &ExtractionSettingsScreen{}
+}
+
+func (s *ExtractionSettingsScreen) Draw(input ExtractionSettingsInput) (ScreenResult[ExtractionSettingsOutput], error) {
+	config := input.Config
+	output := ExtractionSettingsOutput{Config: config}
+
+	items := s.buildMenuItems(config)
+
+	if len(items) == 0 {
+		gaba.GetLogger().Warn("No platforms configured for extraction settings")
+		return back(output), nil
+	}
+
+	result, err := gaba.OptionsList(
+		i18n.Localize(&goi18n.Message{ID: "settings_extraction", Other: "Archive Extraction"}, nil),
+		gaba.OptionListSettings{
+			FooterHelpItems: []gaba.FooterHelpItem{
+				FooterBack(),
+				FooterCycle(),
+				FooterSave(),
+			},
+			InitialSelectedIndex: 0,
+			StatusBar:            StatusBar(),
+			SmallTitle:           true,
+		},
+		items,
+	)
+
+	if err != nil {
+		if errors.Is(err, gaba.ErrCancelled) {
+			return back(output), nil
+		}
+		gaba.GetLogger().Error("Extraction settings error", "error", err)
+		return withCode(output, gaba.ExitCodeError), err
+	}
+
+	s.applySettings(config, result.Items)
+
+	err = internal.SaveConfig(config)
+	if err != nil {
+		gaba.GetLogger().Error("Error saving extraction settings", "error", err)
+		return withCode(output, gaba.ExitCodeError), err
+	}
+
+	return success(output), nil
+}
+
+func (s *ExtractionSettingsScreen) buildMenuItems(config *internal.Config) []gaba.ItemWithOptions {
+	s.displayToFSSlug = make(map[string]string)
+
+	platformNames := make(map[string]string)
+	if cm := cache.GetCacheManager(); cm != nil {
+		if platforms, err := cm.GetPlatforms(); err == nil {
+			for _, p := range platforms {
+				platformNames[p.FSSlug] = p.Name
+			}
+		}
+	}
+
+	fsSlugs := make([]string, 0, len(config.DirectoryMappings))
+	for fsSlug := range config.DirectoryMappings {
+		if !slices.Contains(internal.ArchivePlatforms, fsSlug) {
+			fsSlugs = append(fsSlugs, fsSlug)
+		}
+	}
+	sort.Strings(fsSlugs)
+
+	items := make([]gaba.ItemWithOptions, 0, len(fsSlugs))
+	for _, fsSlug := range fsSlugs {
+		displayName := fsSlug
+		if name, ok := platformNames[fsSlug]; ok {
+			displayName = name
+		}
+		s.displayToFSSlug[displayName] = fsSlug
+
+		items = append(items, gaba.ItemWithOptions{
+			Item: gaba.MenuItem{Text: displayName},
+			Options: []gaba.Option{
+				{DisplayName: i18n.Localize(&goi18n.Message{ID: "extraction_extract", Other: "Extract"}, nil), Value: false},
+				{DisplayName: i18n.Localize(&goi18n.Message{ID: "extraction_keep", Other: "Keep Archived"}, nil), Value: true},
+			},
+			SelectedOption: boolToIndex(config.KeepsArchives(fsSlug)),
+		})
+	}
+
+	return items
+}
+
+func (s *ExtractionSettingsScreen) applySettings(config *internal.Config, items []gaba.ItemWithOptions) {
+	for _, item := range items {
+		fsSlug, ok := s.displayToFSSlug[item.Item.Text]
+		if !ok {
+			continue
+		}
+		if keep, ok := item.Options[item.SelectedOption].Value.(bool); ok {
+			config.SetKeepArchives(fsSlug, keep)
+		}
+	}
+}
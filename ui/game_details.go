@@ -8,9 +8,11 @@ import (
 	constants2 "grout/internal/constants"
 	"grout/internal/imageutil"
 	"grout/internal/stringutil"
+	"grout/sync"
 	"io"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -183,6 +185,15 @@ func (s *GameDetailsScreen) buildSections(input GameDetailsInput) []gaba.Section
 		}))
 	}
 
+	if input.Config != nil && input.Config.SaveSyncMode != "off" {
+		if history := s.buildSaveHistory(input); history != "" {
+			sections = append(sections, gaba.NewDescriptionSection(
+				i18n.Localize(&goi18n.Message{ID: "game_details_save_history", Other: "Save History"}, nil),
+				history,
+			))
+		}
+	}
+
 	qrcode, err := imageutil.CreateTempQRCode(game.GetGamePage(input.Host), 256)
 	if err == nil {
 		sections = append(sections, gaba.NewImageSection(
@@ -200,6 +211,54 @@ func (s *GameDetailsScreen) buildSections(input GameDetailsInput) []gaba.Section
 	return sections
 }
 
+// buildSaveHistory lists the most recent remote saves for the game along with the device that uploaded each one.
+// They are fetched behind a loading message with a short timeout, so being offline only delays the screen briefly.
+func (s *GameDetailsScreen) buildSaveHistory(input GameDetailsInput) string {
+	const maxEntries = 10
+
+	var saves []romm.Save
+	var err error
+	gaba.ProcessMessage(
+		i18n.Localize(&goi18n.Message{ID: "game_details_loading_saves", Other: "Loading save history..."}, nil),
+		gaba.ProcessMessageOptions{ShowThemeBackground: true},
+		func() (interface{}, error) {
+			rc := romm.NewClientFromHost(input.Host, min(input.Config.ApiTimeout, constants2.ValidationTimeout))
+			saves, err = rc.GetSaves(romm.SaveQuery{RomID: input.Game.ID})
+			return nil, nil
+		},
+	)
+	if err != nil {
+		gaba.GetLogger().Warn("Unable to fetch save history", "game", input.Game.Name, "error", err)
+		return ""
+	}
+
+	slices.SortFunc(saves, func(a, b romm.Save) int {
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+
+	lines := make([]string, 0, maxEntries)
+	for i, save := range saves {
+		if i >= maxEntries {
+			break
+		}
+
+		device := sync.SaveDeviceName(save)
+		switch {
+		case sync.IsFromDevice(save, input.Config.DeviceID):
+			device = i18n.Localize(&goi18n.Message{ID: "game_details_save_this_device", Other: "This Device"}, nil)
+		case device == "":
+			device = i18n.Localize(&goi18n.Message{ID: "game_details_save_unknown_device", Other: "Unknown Device"}, nil)
+		}
+
+		lines = append(lines, fmt.Sprintf("%s · %s · %s",
+			save.UpdatedAt.Local().Format("2006-01-02 15:04"),
+			device,
+			stringutil.FormatBytes(int64(save.FileSizeBytes))))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 // getCoverImagePath returns the path to the cover image, using cache if available
 func (s *GameDetailsScreen) getCoverImagePath(host romm.Host, game romm.Rom) string {
 	logger := gaba.GetLogger()
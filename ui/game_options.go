@@ -3,7 +3,9 @@ package ui
 import (
 	"errors"
 	"grout/cfw"
+	"grout/download"
 	"grout/internal"
+	"grout/internal/constants"
 	"grout/romm"
 	"os"
 	"path/filepath"
@@ -15,12 +17,14 @@ import (
 )
 
 type GameOptionsInput struct {
-	Config *internal.Config
-	Game   romm.Rom
+	Config   *internal.Config
+	Platform romm.Platform
+	Game     romm.Rom
 }
 
 type GameOptionsOutput struct {
-	Config *internal.Config
+	Config        *internal.Config
+	RemoveClicked bool
 }
 
 type GameOptionsScreen struct{}
@@ -33,7 +37,7 @@ func (s *GameOptionsScreen) Draw(input GameOptionsInput) (ScreenResult[GameOptio
 	config := input.Config
 	output := GameOptionsOutput{Config: config}
 
-	items := s.buildMenuItems(config, input.Game)
+	items := s.buildMenuItems(config, input.Platform, input.Game)
 
 	if len(items) == 0 {
 		gaba.GetLogger().Warn("No options available for game")
@@ -69,10 +73,16 @@ func (s *GameOptionsScreen) Draw(input GameOptionsInput) (ScreenResult[GameOptio
 		return withCode(output, gaba.ExitCodeError), err
 	}
 
+	if result.Action == gaba.ListActionSelected &&
+		items[result.Selected].Item.Text == i18n.Localize(&goi18n.Message{ID: "game_options_remove", Other: "Remove from Device"}, nil) {
+		output.RemoveClicked = true
+		return withCode(output, constants.ExitCodeRemoveGames), nil
+	}
+
 	return success(output), nil
 }
 
-func (s *GameOptionsScreen) buildMenuItems(config *internal.Config, game romm.Rom) []gaba.ItemWithOptions {
+func (s *GameOptionsScreen) buildMenuItems(config *internal.Config, platform romm.Platform, game romm.Rom) []gaba.ItemWithOptions {
 	items := make([]gaba.ItemWithOptions, 0)
 
 	// Save Directory option
@@ -114,6 +124,26 @@ func (s *GameOptionsScreen) buildMenuItems(config *internal.Config, game romm.Ro
 		})
 	}
 
+	syncSelected := 0
+	if config.IsGameSyncExcluded(game.ID) {
+		syncSelected = 1
+	}
+	items = append(items, gaba.ItemWithOptions{
+		Item: gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "game_options_sync_saves", Other: "Sync Saves"}, nil)},
+		Options: []gaba.Option{
+			{DisplayName: i18n.Localize(&goi18n.Message{ID: "game_options_sync_include", Other: "Include"}, nil), Value: false},
+			{DisplayName: i18n.Localize(&goi18n.Message{ID: "game_options_sync_exclude", Other: "Exclude"}, nil), Value: true},
+		},
+		SelectedOption: syncSelected,
+	})
+
+	if len(download.InstalledPaths(*config, platform, game)) > 0 {
+		items = append(items, gaba.ItemWithOptions{
+			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "game_options_remove", Other: "Remove from Device"}, nil)},
+			Options: []gaba.Option{{Type: gaba.OptionTypeClickable}},
+		})
+	}
+
 	return items
 }
 
@@ -123,6 +153,14 @@ func (s *GameOptionsScreen) applySettings(config *internal.Config, game romm.Rom
 	for _, item := range items {
 		text := item.Item.Text
 
+		if text == i18n.Localize(&goi18n.Message{ID: "game_options_sync_saves", Other: "Sync Saves"}, nil) {
+			if excluded, ok := item.Options[item.SelectedOption].Value.(bool); ok {
+				config.SetGameSyncExcluded(game.ID, excluded)
+				logger.Debug("Save sync exclusion changed", "game", game.Name, "excluded", excluded)
+			}
+			continue
+		}
+
 		if text == i18n.Localize(&goi18n.Message{ID: "game_options_save_directory", Other: "Save Directory"}, nil) {
 			newDir, ok := item.Options[item.SelectedOption].Value.(string)
 			if !ok {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"grout/cache"
+	"grout/download"
 	"grout/internal"
 	"grout/internal/constants"
 	"grout/internal/stringutil"
@@ -41,6 +42,7 @@ type GameListInput struct {
 
 type GameListOutput struct {
 	SelectedGames        []romm.Rom
+	OutdatedGames        []romm.Rom
 	Platform             romm.Platform
 	Collection           romm.Collection
 	SearchFilter         string
@@ -52,6 +54,9 @@ type GameListOutput struct {
 
 type GameListScreen struct{}
 
+// downloadAllRow marks the list row that opens Download All.
+type downloadAllRow struct{}
+
 func NewGameListScreen() *GameListScreen {
 	return &GameListScreen{}
 }
@@ -100,6 +105,22 @@ func (s *GameListScreen) Draw(input GameListInput) (ScreenResult[GameListOutput]
 		displayGames = filteredGames
 	}
 
+	outdatedGames := download.OutdatedGames(*input.Config, input.Platform, games)
+	outdated := make(map[int]bool, len(outdatedGames))
+	for _, game := range outdatedGames {
+		outdated[game.ID] = true
+	}
+
+	badge := func(game romm.Rom) string {
+		switch {
+		case outdated[game.ID]:
+			return gabaconst.Update + " "
+		case input.Config.DownloadedGames == "mark" && game.IsDownloaded(*input.Config):
+			return gabaconst.Download + " "
+		}
+		return ""
+	}
+
 	displayName := input.Platform.Name
 	allGamesFilteredOut := false
 	if isCollectionSet(input.Collection) {
@@ -117,29 +138,17 @@ func (s *GameListScreen) Draw(input GameListInput) (ScreenResult[GameListOutput]
 
 		if input.Platform.ID == 0 {
 			for i := range displayGames {
-				prefix := ""
-				if input.Config.DownloadedGames == "mark" && displayGames[i].IsDownloaded(*input.Config) {
-					prefix = gabaconst.Download + " "
-				}
-				displayGames[i].DisplayName = fmt.Sprintf("%s[%s] %s", prefix, displayGames[i].PlatformFSSlug, displayGames[i].DisplayName)
+				displayGames[i].DisplayName = fmt.Sprintf("%s[%s] %s", badge(displayGames[i]), displayGames[i].PlatformFSSlug, displayGames[i].DisplayName)
 			}
 		} else {
 			displayName = fmt.Sprintf("%s - %s", input.Collection.Name, input.Platform.Name)
-			if input.Config.DownloadedGames == "mark" {
-				for i := range displayGames {
-					if displayGames[i].IsDownloaded(*input.Config) {
-						displayGames[i].DisplayName = fmt.Sprintf("%s %s", gabaconst.Download, displayGames[i].DisplayName)
-					}
-				}
+			for i := range displayGames {
+				displayGames[i].DisplayName = badge(displayGames[i]) + displayGames[i].DisplayName
 			}
 		}
 	} else {
-		if input.Config.DownloadedGames == "mark" {
-			for i := range displayGames {
-				if displayGames[i].IsDownloaded(*input.Config) {
-					displayGames[i].DisplayName = fmt.Sprintf("%s %s", gabaconst.Download, displayGames[i].DisplayName)
-				}
-			}
+		for i := range displayGames {
+			displayGames[i].DisplayName = badge(displayGames[i]) + displayGames[i].DisplayName
 		}
 	}
 
@@ -168,19 +177,34 @@ func (s *GameListScreen) Draw(input GameListInput) (ScreenResult[GameListOutput]
 		return back(output), nil
 	}
 
-	menuItems := make([]gaba.MenuItem, len(displayGames))
-	for i, game := range displayGames {
+	menuItems := make([]gaba.MenuItem, 0, len(displayGames)+1)
+
+	// Rows at the top of the list act on the whole platform or collection
+	if input.SearchFilter == "" && !internal.IsKidModeEnabled() {
+		menuItems = append(menuItems, gaba.MenuItem{
+			Text:     fmt.Sprintf("%s %s", gabaconst.Download, i18n.Localize(&goi18n.Message{ID: "games_list_download_all", Other: "Download All..."}, nil)),
+			Metadata: downloadAllRow{},
+		})
+		if len(outdatedGames) > 0 {
+			menuItems = append(menuItems, gaba.MenuItem{
+				Text:     fmt.Sprintf("%s %s", gabaconst.Update, i18n.Localize(&goi18n.Message{ID: "games_list_update_games", Other: "Update {{.Count}} Games"}, map[string]interface{}{"Count": len(outdatedGames)})),
+				Metadata: outdatedGames,
+			})
+		}
+	}
+
+	for _, game := range displayGames {
 		imageFilename := ""
 		if input.Config.ShowBoxArt {
 			imageFilename = cache.GetArtworkCachePath(game.PlatformFSSlug, game.ID)
 		}
-		menuItems[i] = gaba.MenuItem{
+		menuItems = append(menuItems, gaba.MenuItem{
 			Text:          game.DisplayName,
 			Selected:      false,
 			Focused:       false,
 			Metadata:      game,
 			ImageFilename: imageFilename,
-		}
+		})
 	}
 
 	options := gaba.DefaultListOptions(title, menuItems)
@@ -239,11 +263,30 @@ func (s *GameListScreen) Draw(input GameListInput) (ScreenResult[GameListOutput]
 	switch res.Action {
 	case gaba.ListActionSelected:
 		selectedGames := make([]romm.Rom, 0, len(res.Selected))
+		var updateGames []romm.Rom
+		downloadAll := false
 		for _, idx := range res.Selected {
-			selectedGames = append(selectedGames, res.Items[idx].Metadata.(romm.Rom))
+			switch metadata := res.Items[idx].Metadata.(type) {
+			case romm.Rom:
+				selectedGames = append(selectedGames, metadata)
+			case []romm.Rom:
+				updateGames = metadata
+			case downloadAllRow:
+				downloadAll = true
+			}
 		}
 		output.LastSelectedIndex = res.Selected[0]
 		output.LastSelectedPosition = res.VisiblePosition
+
+		// The top rows are ignored when they are swept up in a multi-select with games
+		if len(selectedGames) == 0 {
+			if downloadAll {
+				return withCode(output, constants.ExitCodeDownloadAll), nil
+			}
+			output.OutdatedGames = updateGames
+			return withCode(output, constants.ExitCodeUpdateGames), nil
+		}
+
 		output.SelectedGames = selectedGames
 		return success(output), nil
 
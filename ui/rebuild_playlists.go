@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"grout/download"
+	"grout/internal"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type RebuildPlaylistsOutput struct{}
+
+type RebuildPlaylistsScreen struct{}
+
+func NewRebuildPlaylistsScreen() *RebuildPlaylistsScreen {
+	return &RebuildPlaylistsScreen{}
+}
+
+// Execute writes the playlists missing for multi-disc games already on the device and
+// says how many were written.
+func (s *RebuildPlaylistsScreen) Execute(config internal.Config) RebuildPlaylistsOutput {
+	var written int
+	var err error
+	gaba.ProcessMessage(
+		i18n.Localize(&goi18n.Message{ID: "rebuild_playlists_working", Other: "Looking for multi-disc games..."}, nil),
+		gaba.ProcessMessageOptions{ShowThemeBackground: true},
+		func() (interface{}, error) {
+			written, err = download.RebuildPlaylists(config)
+			return nil, nil
+		},
+	)
+
+	var message string
+	switch {
+	case err != nil:
+		gaba.GetLogger().Error("Unable to rebuild playlists", "error", err)
+		message = i18n.Localize(&goi18n.Message{ID: "rebuild_playlists_failed", Other: "{{.Count}} playlists were written, but some could not be.\nCheck the log for details."},
+			map[string]interface{}{"Count": written})
+	case written == 0:
+		message = i18n.Localize(&goi18n.Message{ID: "rebuild_playlists_none", Other: "Every multi-disc game already has a playlist."}, nil)
+	default:
+		message = i18n.Localize(&goi18n.Message{ID: "rebuild_playlists_done", Other: "Wrote {{.Count}} playlists."},
+			map[string]interface{}{"Count": written})
+	}
+
+	gaba.ConfirmationMessage(message, ContinueFooter(), gaba.MessageOptions{})
+	return RebuildPlaylistsOutput{}
+}
@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"errors"
+	"grout/download"
+	"grout/internal"
+	"grout/internal/stringutil"
+	"grout/romm"
+	"grout/sync"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type RemoveGamesInput struct {
+	Config   *internal.Config
+	Host     romm.Host
+	Platform romm.Platform
+	Games    []romm.Rom
+}
+
+type RemoveGamesOutput struct {
+	Removed int
+}
+
+type RemoveGamesScreen struct{}
+
+func NewRemoveGamesScreen() *RemoveGamesScreen {
+	return &RemoveGamesScreen{}
+}
+
+func (s *RemoveGamesScreen) Execute(config *internal.Config, host romm.Host, platform romm.Platform, games []romm.Rom) RemoveGamesOutput {
+	return s.draw(RemoveGamesInput{
+		Config:   config,
+		Host:     host,
+		Platform: platform,
+		Games:    games,
+	})
+}
+
+// draw removes games from the device once the user has seen how much space it frees,
+// and deletes their saves as well if asked to.
+func (s *RemoveGamesScreen) draw(input RemoveGamesInput) RemoveGamesOutput {
+	logger := gaba.GetLogger()
+	output := RemoveGamesOutput{}
+
+	var installed []romm.Rom
+	var size int64
+	for _, game := range input.Games {
+		if gameSize := download.InstalledSize(*input.Config, input.Platform, game); gameSize > 0 {
+			installed = append(installed, game)
+			size += gameSize
+		}
+	}
+
+	if len(installed) == 0 {
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "remove_games_none", Other: "None of these games are on this device."}, nil),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+		return output
+	}
+
+	var message string
+	if len(installed) == 1 {
+		message = i18n.Localize(&goi18n.Message{ID: "remove_games_confirm_one", Other: "Remove {{.Name}} from this device?\nThis frees {{.Size}}."},
+			map[string]interface{}{"Name": installed[0].Name, "Size": stringutil.FormatBytes(size)})
+	} else {
+		message = i18n.Localize(&goi18n.Message{ID: "remove_games_confirm", Other: "Remove {{.Count}} games from this device?\nThis frees {{.Size}}."},
+			map[string]interface{}{"Count": len(installed), "Size": stringutil.FormatBytes(size)})
+	}
+
+	_, err := gaba.ConfirmationMessage(
+		message,
+		[]gaba.FooterHelpItem{
+			FooterCancel(),
+			footerItem("A", "button_remove", "Remove"),
+		},
+		gaba.MessageOptions{},
+	)
+	if err != nil {
+		if !errors.Is(err, gaba.ErrCancelled) {
+			logger.Error("Remove games confirmation error", "error", err)
+		}
+		return output
+	}
+
+	_, err = gaba.ConfirmationMessage(
+		i18n.Localize(&goi18n.Message{ID: "remove_games_saves", Other: "Delete the saves as well?\nSaves RomM doesn't have yet are uploaded first."}, nil),
+		[]gaba.FooterHelpItem{
+			footerItem("B", "button_keep_saves", "Keep Saves"),
+			footerItem("A", "button_delete_saves", "Delete Saves"),
+		},
+		gaba.MessageOptions{},
+	)
+	deleteSaves := err == nil
+
+	var savesErr error
+	var removeErrs []error
+	gaba.ProcessMessage(
+		i18n.Localize(&goi18n.Message{ID: "remove_games_removing", Other: "Removing games..."}, nil),
+		gaba.ProcessMessageOptions{ShowThemeBackground: true},
+		func() (interface{}, error) {
+			if deleteSaves {
+				romPaths := make([]string, 0, len(installed))
+				for _, game := range installed {
+					romPaths = append(romPaths, download.InstalledPaths(*input.Config, input.Platform, game)...)
+				}
+				_, savesErr = sync.RemoveGameSaves(input.Host, input.Config, romPaths)
+			}
+
+			for _, game := range installed {
+				if err := download.Uninstall(*input.Config, input.Platform, game); err != nil {
+					logger.Error("Unable to remove game", "game", game.Name, "error", err)
+					removeErrs = append(removeErrs, err)
+					continue
+				}
+				output.Removed++
+			}
+			return nil, nil
+		},
+	)
+
+	if savesErr != nil {
+		logger.Error("Unable to remove saves", "error", savesErr)
+	}
+
+	switch {
+	case len(removeErrs) > 0:
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "remove_games_failed", Other: "Some files could not be removed."}, nil),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+	case savesErr != nil:
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "remove_games_saves_kept", Other: "Some saves could not be uploaded, so they were kept on the device."}, nil),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+	}
+
+	return output
+}
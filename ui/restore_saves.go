@@ -0,0 +1,191 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"grout/internal"
+	"grout/romm"
+	"grout/sync"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	icons "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/constants"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"go.uber.org/atomic"
+)
+
+type RestoreSavesInput struct {
+	Config *internal.Config
+	Host   romm.Host
+}
+
+type RestoreSavesOutput struct{}
+
+type RestoreSavesScreen struct{}
+
+func NewRestoreSavesScreen() *RestoreSavesScreen {
+	return &RestoreSavesScreen{}
+}
+
+func (s *RestoreSavesScreen) Execute(config *internal.Config, host romm.Host) RestoreSavesOutput {
+	s.draw(RestoreSavesInput{
+		Config: config,
+		Host:   host,
+	})
+	return RestoreSavesOutput{}
+}
+
+func (s *RestoreSavesScreen) draw(input RestoreSavesInput) {
+	logger := gaba.GetLogger()
+
+	var items []sync.RestoreItem
+	var findErr error
+	gaba.ProcessMessage(
+		i18n.Localize(&goi18n.Message{ID: "restore_saves_finding", Other: "Looking for saves on RomM..."}, nil),
+		gaba.ProcessMessageOptions{ShowThemeBackground: true},
+		func() (interface{}, error) {
+			items, findErr = sync.FindRestorableSaves(input.Host, input.Config)
+			return nil, nil
+		},
+	)
+
+	if findErr != nil {
+		logger.Error("Unable to find restorable saves", "error", findErr)
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "restore_saves_failed", Other: "Unable to fetch saves from RomM."}, nil),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+		return
+	}
+
+	if len(items) == 0 {
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "restore_saves_none", Other: "There are no saves to restore."}, nil),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+		return
+	}
+
+	selected, err := s.showPlan(items)
+	if err != nil || len(selected) == 0 {
+		return
+	}
+
+	var missingRoms []sync.RestoreItem
+	for _, item := range selected {
+		if !item.RomPresent {
+			missingRoms = append(missingRoms, item)
+		}
+	}
+
+	if len(missingRoms) > 0 {
+		_, err := gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "restore_saves_download_roms", Other: "{{.Count}} of these games are not on this device.\nDownload them too?"}, map[string]interface{}{"Count": len(missingRoms)}),
+			[]gaba.FooterHelpItem{
+				footerItem("B", "button_saves_only", "Saves Only"),
+				footerItem("A", "button_download", "Download"),
+			},
+			gaba.MessageOptions{},
+		)
+		if err == nil {
+			s.downloadRoms(input, missingRoms)
+		} else if !errors.Is(err, gaba.ErrCancelled) {
+			logger.Error("Restore confirmation error", "error", err)
+		}
+	}
+
+	results := make([]sync.SyncResult, 0, len(selected))
+	progress := &atomic.Float64{}
+
+	gaba.ProcessMessage(
+		i18n.Localize(&goi18n.Message{ID: "restore_saves_restoring", Other: "Restoring saves..."}, nil),
+		gaba.ProcessMessageOptions{
+			ShowProgressBar: true,
+			Progress:        progress,
+		},
+		func() (interface{}, error) {
+			for i := range selected {
+				result := selected[i].Sync.Execute(input.Host, input.Config)
+				results = append(results, result)
+				if !result.Success {
+					logger.Error("Unable to restore save", "game", selected[i].Sync.GameBase, "error", result.Error)
+				}
+				progress.Store(float64(i+1) / float64(len(selected)))
+			}
+			return nil, nil
+		},
+	)
+
+	if _, err := newSyncReportScreen().draw(syncReportInput{
+		Results:    results,
+		DeviceName: input.Config.DeviceName,
+	}); err != nil {
+		logger.Error("Error showing restore report", "error", err)
+	}
+}
+
+// showPlan lists every save that would be written and lets the user deselect any of them.
+func (s *RestoreSavesScreen) showPlan(items []sync.RestoreItem) ([]sync.RestoreItem, error) {
+	menuItems := make([]gaba.MenuItem, len(items))
+	for i, item := range items {
+		text := fmt.Sprintf("%s · %s", item.Platform.Name, item.Rom.Name)
+		if !item.RomPresent {
+			text = i18n.Localize(&goi18n.Message{ID: "restore_saves_rom_missing", Other: "{{.Name}} (ROM missing)"}, map[string]interface{}{"Name": text})
+		}
+		menuItems[i] = gaba.MenuItem{
+			Text:     text,
+			Selected: true,
+			Metadata: item,
+		}
+	}
+
+	options := gaba.DefaultListOptions(
+		i18n.Localize(&goi18n.Message{ID: "restore_saves_title", Other: "Restore Saves"}, nil),
+		menuItems,
+	)
+	options.FooterHelpItems = []gaba.FooterHelpItem{
+		FooterCancel(),
+		{ButtonName: icons.Start, HelpText: i18n.Localize(&goi18n.Message{ID: "button_restore", Other: "Restore"}, nil), IsConfirmButton: true},
+	}
+	options.StartInMultiSelectMode = true
+	options.StatusBar = StatusBar()
+	options.SmallTitle = true
+
+	result, err := gaba.List(options)
+	if err != nil {
+		if !errors.Is(err, gaba.ErrCancelled) {
+			gaba.GetLogger().Error("Restore plan error", "error", err)
+		}
+		return nil, err
+	}
+
+	selected := make([]sync.RestoreItem, 0, len(result.Selected))
+	for _, idx := range result.Selected {
+		if idx >= 0 && idx < len(items) {
+			selected = append(selected, items[idx])
+		}
+	}
+
+	return selected, nil
+}
+
+func (s *RestoreSavesScreen) downloadRoms(input RestoreSavesInput, items []sync.RestoreItem) {
+	var platformOrder []int
+	gamesByPlatform := make(map[int][]romm.Rom)
+	platforms := make(map[int]romm.Platform)
+
+	for _, item := range items {
+		if _, seen := platforms[item.Platform.ID]; !seen {
+			platformOrder = append(platformOrder, item.Platform.ID)
+			platforms[item.Platform.ID] = item.Platform
+		}
+		gamesByPlatform[item.Platform.ID] = append(gamesByPlatform[item.Platform.ID], item.Rom)
+	}
+
+	for _, platformID := range platformOrder {
+		games := gamesByPlatform[platformID]
+		NewDownloadScreen().Execute(*input.Config, input.Host, platforms[platformID], games, games, "")
+	}
+}
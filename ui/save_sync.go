@@ -4,6 +4,8 @@ import (
 	"grout/internal"
 	"grout/romm"
 	"grout/sync"
+	"path/filepath"
+	"strings"
 	"time"
 
 	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
@@ -60,39 +62,67 @@ func (s *SaveSyncScreen) Draw(input SaveSyncInput) (ScreenResult[SaveSyncOutput]
 
 	if scan, ok := scanData.(scanResult); ok {
 		unmatched = scan.Unmatched
-		results = make([]sync.SyncResult, 0, len(scan.Syncs))
-
-		if len(scan.Syncs) > 0 {
-			progress := &atomic.Float64{}
-
-			gaba.ProcessMessage(
-				i18n.Localize(&goi18n.Message{ID: "save_sync_syncing", Other: "Syncing saves..."}, nil),
-				gaba.ProcessMessageOptions{
-					ShowProgressBar: true,
-					Progress:        progress,
-				},
-				func() (interface{}, error) {
-					total := len(scan.Syncs)
-					for i := range scan.Syncs {
-						s := &scan.Syncs[i]
-						result := s.Execute(input.Host, input.Config)
-						results = append(results, result)
-						if !result.Success {
-							gaba.GetLogger().Error("Unable to sync save!", "game", s.GameBase, "error", result.Error)
+
+		if hasPendingSyncs(scan.Syncs) {
+			preview, err := newSyncPreviewScreen().draw(syncPreviewInput{Syncs: scan.Syncs})
+			if err != nil || preview.ExitCode != gaba.ExitCodeSuccess {
+				return back(output), err
+			}
+
+			toRun := preview.Value.Selected
+			results = make([]sync.SyncResult, 0, len(scan.Syncs))
+			for _, ss := range preview.Value.Deselected {
+				results = append(results, skippedResult(ss))
+			}
+			for _, ss := range scan.Syncs {
+				if ss.Action == sync.Skip {
+					results = append(results, skippedResult(ss))
+				}
+			}
+
+			if len(toRun) > 0 {
+				progress := &atomic.Float64{}
+
+				gaba.ProcessMessage(
+					i18n.Localize(&goi18n.Message{ID: "save_sync_syncing", Other: "Syncing saves..."}, nil),
+					gaba.ProcessMessageOptions{
+						ShowProgressBar: true,
+						Progress:        progress,
+					},
+					func() (interface{}, error) {
+						total := len(toRun)
+						for i := range toRun {
+							s := &toRun[i]
+							result := s.Execute(input.Host, input.Config)
+							results = append(results, result)
+							if !result.Success {
+								gaba.GetLogger().Error("Unable to sync save!", "game", s.GameBase, "error", result.Error)
+							}
+							progress.Store(float64(i+1) / float64(total))
 						}
-						progress.Store(float64(i+1) / float64(total))
-					}
-					return nil, nil
-				},
-			)
+						return nil, nil
+					},
+				)
+			}
+		} else {
+			// Nothing to sync, but excluded saves are still listed so they aren't forgotten
+			for _, ss := range scan.Syncs {
+				if ss.Reason.Excluded() {
+					results = append(results, skippedResult(ss))
+				}
+			}
 		}
 	}
 
-	if len(results) > 0 || len(unmatched) > 0 {
+	queued := sync.QueuedSyncs()
+
+	if len(results) > 0 || len(unmatched) > 0 || len(queued) > 0 {
 		reportScreen := newSyncReportScreen()
 		_, err := reportScreen.draw(syncReportInput{
-			Results:   results,
-			Unmatched: unmatched,
+			Results:    results,
+			Unmatched:  unmatched,
+			Queued:     queued,
+			DeviceName: input.Config.DeviceName,
 		})
 		if err != nil {
 			gaba.GetLogger().Error("Error showing sync report", "error", err)
@@ -106,3 +136,22 @@ func (s *SaveSyncScreen) Draw(input SaveSyncInput) (ScreenResult[SaveSyncOutput]
 
 	return back(output), nil
 }
+
+func hasPendingSyncs(syncs []sync.SaveSync) bool {
+	for _, s := range syncs {
+		if s.Action != sync.Skip {
+			return true
+		}
+	}
+	return false
+}
+
+func skippedResult(s sync.SaveSync) sync.SyncResult {
+	return sync.SyncResult{
+		GameName:       s.GameBase,
+		RomDisplayName: strings.TrimSuffix(s.RomName, filepath.Ext(s.RomName)),
+		Action:         sync.Skip,
+		Reason:         s.Reason,
+		Success:        true,
+	}
+}
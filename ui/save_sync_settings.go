@@ -6,12 +6,25 @@ import (
 	"grout/cfw"
 	"grout/internal"
 	"sort"
+	"strings"
+	"time"
 
 	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
 	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
 	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
 )
 
+// saveSyncExcludedOption is the platform option value that leaves the platform out of
+// save sync. Emulator folder names never contain a leading colon.
+const saveSyncExcludedOption = ":excluded"
+
+// The settings listed above the platforms, in order.
+const (
+	deviceNameItem = iota
+	syncIntervalItem
+	saveRetentionItem
+)
+
 type SaveSyncSettingsInput struct {
 	Config *internal.Config
 	CFW    cfw.CFW
@@ -78,6 +91,32 @@ func (s *SaveSyncSettingsScreen) buildMenuItems(config *internal.Config) []gaba.
 	items := make([]gaba.ItemWithOptions, 0)
 	s.displayToFSSlug = make(map[string]string)
 
+	items = append(items, gaba.ItemWithOptions{
+		Item: gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "save_sync_device_name", Other: "Device Name"}, nil)},
+		Options: []gaba.Option{
+			{
+				Type:           gaba.OptionTypeKeyboard,
+				DisplayName:    config.DeviceName,
+				KeyboardPrompt: config.DeviceName,
+				Value:          config.DeviceName,
+			},
+		},
+	})
+
+	intervals := syncIntervalOptions()
+	items = append(items, gaba.ItemWithOptions{
+		Item:           gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "save_sync_interval", Other: "Background Sync"}, nil)},
+		Options:        intervals,
+		SelectedOption: findSyncIntervalIndex(intervals, config.SaveSyncInterval),
+	})
+
+	retention := saveRetentionOptions()
+	items = append(items, gaba.ItemWithOptions{
+		Item:           gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "save_sync_retention", Other: "Keep Server Saves"}, nil)},
+		Options:        retention,
+		SelectedOption: findSaveRetentionIndex(retention, config.SaveRetention),
+	})
+
 	// Build a map of fsSlug -> platform display name from cache
 	platformNames := make(map[string]string)
 	if cm := cache.GetCacheManager(); cm != nil {
@@ -117,9 +156,17 @@ func (s *SaveSyncSettingsScreen) buildMenuItems(config *internal.Config) []gaba.
 			})
 		}
 
+		// Excluding the platform keeps its save directory mapping for when it is included again
+		options = append(options, gaba.Option{
+			DisplayName: i18n.Localize(&goi18n.Message{ID: "save_sync_settings_excluded", Other: "Don't Sync"}, nil),
+			Value:       saveSyncExcludedOption,
+		})
+
 		// Determine currently selected option
 		selectedIndex := 0
-		if config.SaveDirectoryMappings != nil {
+		if config.IsPlatformSyncExcluded(fsSlug) {
+			selectedIndex = len(options) - 1
+		} else if config.SaveDirectoryMappings != nil {
 			if currentMapping, ok := config.SaveDirectoryMappings[fsSlug]; ok && currentMapping != "" {
 				for i, opt := range options {
 					if val, ok := opt.Value.(string); ok && val == currentMapping {
@@ -154,13 +201,35 @@ func (s *SaveSyncSettingsScreen) applySettings(config *internal.Config, items []
 		config.SaveDirectoryMappings = make(map[string]string)
 	}
 
-	for _, item := range items {
+	for i, item := range items {
+		switch i {
+		case deviceNameItem:
+			if val, ok := item.Options[item.SelectedOption].Value.(string); ok && strings.TrimSpace(val) != "" {
+				config.DeviceName = strings.TrimSpace(val)
+			}
+			continue
+		case syncIntervalItem:
+			if val, ok := item.Options[item.SelectedOption].Value.(time.Duration); ok {
+				config.SaveSyncInterval = val
+			}
+			continue
+		case saveRetentionItem:
+			if val, ok := item.Options[item.SelectedOption].Value.(int); ok {
+				config.SaveRetention = val
+			}
+			continue
+		}
+
 		// Look up fsSlug from display name
 		fsSlug, ok := s.displayToFSSlug[item.Item.Text]
 		if !ok {
 			continue
 		}
 		if val, ok := item.Options[item.SelectedOption].Value.(string); ok {
+			config.SetPlatformSyncExcluded(fsSlug, val == saveSyncExcludedOption)
+			if val == saveSyncExcludedOption {
+				continue
+			}
 			if val == "" {
 				// Remove from map if set to default
 				delete(config.SaveDirectoryMappings, fsSlug)
@@ -170,3 +239,48 @@ func (s *SaveSyncSettingsScreen) applySettings(config *internal.Config, items []
 		}
 	}
 }
+
+// syncIntervalOptions lists how often automatic sync re-runs while Grout is open.
+// Off still syncs on launch, on reconnect and when a save changes.
+func syncIntervalOptions() []gaba.Option {
+	return []gaba.Option{
+		{DisplayName: i18n.Localize(&goi18n.Message{ID: "save_sync_interval_off", Other: "Off"}, nil), Value: time.Duration(0)},
+		{DisplayName: i18n.Localize(&goi18n.Message{ID: "time_5_minutes", Other: "5 Minutes"}, nil), Value: 5 * time.Minute},
+		{DisplayName: i18n.Localize(&goi18n.Message{ID: "time_15_minutes", Other: "15 Minutes"}, nil), Value: 15 * time.Minute},
+		{DisplayName: i18n.Localize(&goi18n.Message{ID: "time_30_minutes", Other: "30 Minutes"}, nil), Value: 30 * time.Minute},
+		{DisplayName: i18n.Localize(&goi18n.Message{ID: "time_60_minutes", Other: "60 Minutes"}, nil), Value: 60 * time.Minute},
+	}
+}
+
+func findSyncIntervalIndex(options []gaba.Option, interval time.Duration) int {
+	for i, opt := range options {
+		if val, ok := opt.Value.(time.Duration); ok && val == interval {
+			return i
+		}
+	}
+	return 0
+}
+
+// saveRetentionOptions lists how many saves per game and device are kept on RomM after
+// an upload. Older ones are deleted from the server.
+func saveRetentionOptions() []gaba.Option {
+	options := []gaba.Option{
+		{DisplayName: i18n.Localize(&goi18n.Message{ID: "save_sync_retention_all", Other: "All"}, nil), Value: 0},
+	}
+	for _, keep := range []int{1, 3, 5, 10, 20} {
+		options = append(options, gaba.Option{
+			DisplayName: i18n.Localize(&goi18n.Message{ID: "save_sync_retention_newest", Other: "Newest {{.Count}}"}, map[string]interface{}{"Count": keep}),
+			Value:       keep,
+		})
+	}
+	return options
+}
+
+func findSaveRetentionIndex(options []gaba.Option, keep int) int {
+	for i, opt := range options {
+		if val, ok := opt.Value.(int); ok && val == keep {
+			return i
+		}
+	}
+	return 0
+}
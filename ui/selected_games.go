@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"errors"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// SelectedGamesAction is what to do with games picked in multi-select mode.
+type SelectedGamesAction int
+
+const (
+	SelectedGamesDownload SelectedGamesAction = iota
+	SelectedGamesRemove
+)
+
+type SelectedGamesScreen struct{}
+
+func NewSelectedGamesScreen() *SelectedGamesScreen {
+	return &SelectedGamesScreen{}
+}
+
+// Execute asks whether to download the selected games or remove them from the device.
+// It is only needed when some of them are already downloaded.
+func (s *SelectedGamesScreen) Execute(count int) (SelectedGamesAction, bool) {
+	menuItems := []gaba.MenuItem{
+		{
+			Text:     i18n.Localize(&goi18n.Message{ID: "selected_games_download", Other: "Download"}, nil),
+			Metadata: SelectedGamesDownload,
+		},
+		{
+			Text:     i18n.Localize(&goi18n.Message{ID: "selected_games_remove", Other: "Remove from Device"}, nil),
+			Metadata: SelectedGamesRemove,
+		},
+	}
+
+	options := gaba.DefaultListOptions(
+		i18n.Localize(&goi18n.Message{ID: "selected_games_title", Other: "{{.Count}} Games Selected"}, map[string]interface{}{"Count": count}),
+		menuItems,
+	)
+	options.FooterHelpItems = BackSelectFooter()
+	options.StatusBar = StatusBar()
+	options.SmallTitle = true
+
+	result, err := gaba.List(options)
+	if err != nil {
+		if !errors.Is(err, gaba.ErrCancelled) {
+			gaba.GetLogger().Error("Selected games action error", "error", err)
+		}
+		return SelectedGamesDownload, false
+	}
+
+	if len(result.Selected) == 0 {
+		return SelectedGamesDownload, false
+	}
+
+	return result.Items[result.Selected[0]].Metadata.(SelectedGamesAction), true
+}
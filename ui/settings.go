@@ -31,9 +31,14 @@ type SettingsOutput struct {
 	GeneralSettingsClicked     bool
 	InfoClicked                bool
 	CollectionsSettingsClicked bool
+	ExtractionSettingsClicked  bool
 	DirectoryMappingsClicked   bool
 	AdvancedSettingsClicked    bool
 	SaveSyncSettingsClicked    bool
+	RestoreSavesClicked        bool
+	CleanUpSavesClicked        bool
+	DownloadQueueClicked       bool
+	ActivityClicked            bool
 	CheckUpdatesClicked        bool
 	LastSelectedIndex          int
 	LastVisibleStartIndex      int
@@ -50,9 +55,14 @@ type SettingType string
 const (
 	SettingGeneralSettings     SettingType = "general_settings"
 	SettingCollectionsSettings SettingType = "collections_settings"
+	SettingExtractionSettings  SettingType = "extraction_settings"
 	SettingDirectoryMappings   SettingType = "directory_mappings"
+	SettingDownloadQueue       SettingType = "download_queue"
+	SettingActivity            SettingType = "activity"
 	SettingSaveSync            SettingType = "save_sync"
 	SettingSaveSyncSettings    SettingType = "save_sync_settings"
+	SettingRestoreSaves        SettingType = "restore_saves"
+	SettingCleanUpSaves        SettingType = "clean_up_saves"
 	SettingAdvancedSettings    SettingType = "advanced_settings"
 	SettingInfo                SettingType = "info"
 	SettingCheckUpdates        SettingType = "check_updates"
@@ -61,9 +71,14 @@ const (
 var settingsOrder = []SettingType{
 	SettingGeneralSettings,
 	SettingCollectionsSettings,
+	SettingExtractionSettings,
 	SettingDirectoryMappings,
+	SettingDownloadQueue,
+	SettingActivity,
 	SettingSaveSync,
 	SettingSaveSyncSettings,
+	SettingRestoreSaves,
+	SettingCleanUpSaves,
 	SettingAdvancedSettings,
 	SettingInfo,
 	SettingCheckUpdates,
@@ -122,11 +137,26 @@ func (s *SettingsScreen) Draw(input SettingsInput) (ScreenResult[SettingsOutput]
 			return withCode(output, constants.ExitCodeCollectionsSettings), nil
 		}
 
+		if selectedText == i18n.Localize(&goi18n.Message{ID: "settings_extraction", Other: "Archive Extraction"}, nil) {
+			output.ExtractionSettingsClicked = true
+			return withCode(output, constants.ExitCodeExtractionSettings), nil
+		}
+
 		if selectedText == i18n.Localize(&goi18n.Message{ID: "settings_edit_mappings", Other: "Directory Mappings"}, nil) {
 			output.DirectoryMappingsClicked = true
 			return withCode(output, constants.ExitCodeEditMappings), nil
 		}
 
+		if selectedText == i18n.Localize(&goi18n.Message{ID: "settings_download_queue", Other: "Download Queue"}, nil) {
+			output.DownloadQueueClicked = true
+			return withCode(output, constants.ExitCodeDownloadQueue), nil
+		}
+
+		if selectedText == i18n.Localize(&goi18n.Message{ID: "settings_activity", Other: "Recent Activity"}, nil) {
+			output.ActivityClicked = true
+			return withCode(output, constants.ExitCodeActivity), nil
+		}
+
 		if selectedText == i18n.Localize(&goi18n.Message{ID: "settings_advanced", Other: "Advanced"}, nil) {
 			output.AdvancedSettingsClicked = true
 			return withCode(output, constants.ExitCodeAdvancedSettings), nil
@@ -137,6 +167,16 @@ func (s *SettingsScreen) Draw(input SettingsInput) (ScreenResult[SettingsOutput]
 			return withCode(output, constants.ExitCodeSaveSyncSettings), nil
 		}
 
+		if selectedText == i18n.Localize(&goi18n.Message{ID: "settings_restore_saves", Other: "Restore Saves"}, nil) {
+			output.RestoreSavesClicked = true
+			return withCode(output, constants.ExitCodeRestoreSaves), nil
+		}
+
+		if selectedText == i18n.Localize(&goi18n.Message{ID: "settings_clean_up_saves", Other: "Clean Up Server Saves"}, nil) {
+			output.CleanUpSavesClicked = true
+			return withCode(output, constants.ExitCodeCleanUpSaves), nil
+		}
+
 		if selectedText == i18n.Localize(&goi18n.Message{ID: "update_check_for_updates", Other: "Check for Updates"}, nil) {
 			output.CheckUpdatesClicked = true
 			return withCode(output, constants.ExitCodeCheckUpdate), nil
@@ -169,12 +209,30 @@ func (s *SettingsScreen) buildMenuItem(settingType SettingType, config *internal
 			Options: []gaba.Option{{Type: gaba.OptionTypeClickable}},
 		}
 
+	case SettingExtractionSettings:
+		return gaba.ItemWithOptions{
+			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "settings_extraction", Other: "Archive Extraction"}, nil)},
+			Options: []gaba.Option{{Type: gaba.OptionTypeClickable}},
+		}
+
 	case SettingDirectoryMappings:
 		return gaba.ItemWithOptions{
 			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "settings_edit_mappings", Other: "Directory Mappings"}, nil)},
 			Options: []gaba.Option{{Type: gaba.OptionTypeClickable}},
 		}
 
+	case SettingDownloadQueue:
+		return gaba.ItemWithOptions{
+			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "settings_download_queue", Other: "Download Queue"}, nil)},
+			Options: []gaba.Option{{Type: gaba.OptionTypeClickable}},
+		}
+
+	case SettingActivity:
+		return gaba.ItemWithOptions{
+			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "settings_activity", Other: "Recent Activity"}, nil)},
+			Options: []gaba.Option{{Type: gaba.OptionTypeClickable}},
+		}
+
 	case SettingSaveSync:
 		return gaba.ItemWithOptions{
 			Item: gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "settings_save_sync", Other: "Save Sync"}, nil)},
@@ -199,6 +257,20 @@ func (s *SettingsScreen) buildMenuItem(settingType SettingType, config *internal
 			VisibleWhen: &visibility.saveSyncSettings,
 		}
 
+	case SettingRestoreSaves:
+		return gaba.ItemWithOptions{
+			Item:        gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "settings_restore_saves", Other: "Restore Saves"}, nil)},
+			Options:     []gaba.Option{{Type: gaba.OptionTypeClickable}},
+			VisibleWhen: &visibility.saveSyncSettings,
+		}
+
+	case SettingCleanUpSaves:
+		return gaba.ItemWithOptions{
+			Item:        gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "settings_clean_up_saves", Other: "Clean Up Server Saves"}, nil)},
+			Options:     []gaba.Option{{Type: gaba.OptionTypeClickable}},
+			VisibleWhen: &visibility.saveSyncSettings,
+		}
+
 	case SettingAdvancedSettings:
 		return gaba.ItemWithOptions{
 			Item:    gaba.MenuItem{Text: i18n.Localize(&goi18n.Message{ID: "settings_advanced", Other: "Advanced"}, nil)},
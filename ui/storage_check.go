@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"grout/cache"
+	"grout/download"
+	"grout/internal/stringutil"
+	"strings"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	icons "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/constants"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type StorageCheckScreen struct{}
+
+func NewStorageCheckScreen() *StorageCheckScreen {
+	return &StorageCheckScreen{}
+}
+
+// Execute warns when the planned downloads won't fit on the device and lets the user
+// deselect games until they do. It returns the downloads to go ahead with, or false if
+// the user backed out.
+func (s *StorageCheckScreen) Execute(planned []cache.QueuedDownload, check func([]cache.QueuedDownload) []download.SpaceShortfall) ([]cache.QueuedDownload, bool) {
+	logger := gaba.GetLogger()
+	selected := planned
+
+	for {
+		shortfalls := check(selected)
+		if len(shortfalls) == 0 {
+			return selected, true
+		}
+
+		for _, sf := range shortfalls {
+			logger.Warn("Not enough free space for downloads", "path", sf.Path, "needed", sf.Needed, "free", sf.Free)
+		}
+
+		_, err := gaba.ConfirmationMessage(
+			s.shortfallMessage(shortfalls),
+			[]gaba.FooterHelpItem{
+				FooterCancel(),
+				footerItem("A", "button_choose_games", "Choose Games"),
+			},
+			gaba.MessageOptions{},
+		)
+		if err != nil {
+			return nil, false
+		}
+
+		selected, err = s.chooseGames(planned, selected, shortfalls)
+		if err != nil || len(selected) == 0 {
+			return nil, false
+		}
+	}
+}
+
+func (s *StorageCheckScreen) shortfallMessage(shortfalls []download.SpaceShortfall) string {
+	lines := []string{i18n.Localize(&goi18n.Message{ID: "storage_check_not_enough", Other: "There isn't enough free space for these downloads."}, nil)}
+	for _, sf := range shortfalls {
+		lines = append(lines, i18n.Localize(&goi18n.Message{ID: "storage_check_shortfall", Other: "{{.Path}}: needs {{.Needed}}, {{.Free}} free"},
+			map[string]interface{}{
+				"Path":   sf.Path,
+				"Needed": stringutil.FormatBytes(int64(sf.Needed)),
+				"Free":   stringutil.FormatBytes(int64(sf.Free)),
+			}))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// chooseGames lists the planned downloads with their sizes, keeping the current
+// selection, so the user can drop games until the rest fit.
+func (s *StorageCheckScreen) chooseGames(planned, selected []cache.QueuedDownload, shortfalls []download.SpaceShortfall) ([]cache.QueuedDownload, error) {
+	isSelected := make(map[int]bool, len(selected))
+	for _, d := range selected {
+		isSelected[d.Rom.ID] = true
+	}
+
+	var over uint64
+	for _, sf := range shortfalls {
+		over = max(over, sf.Needed-sf.Free)
+	}
+
+	menuItems := make([]gaba.MenuItem, len(planned))
+	for i, d := range planned {
+		menuItems[i] = gaba.MenuItem{
+			Text:     fmt.Sprintf("%s · %s", d.Rom.Name, stringutil.FormatBytes(int64(download.EstimatedSize(d)))),
+			Selected: isSelected[d.Rom.ID],
+			Metadata: d,
+		}
+	}
+
+	options := gaba.DefaultListOptions(
+		i18n.Localize(&goi18n.Message{ID: "storage_check_title", Other: "Free Up {{.Size}}"}, map[string]interface{}{"Size": stringutil.FormatBytes(int64(over))}),
+		menuItems,
+	)
+	options.FooterHelpItems = []gaba.FooterHelpItem{
+		FooterCancel(),
+		{ButtonName: icons.Start, HelpText: i18n.Localize(&goi18n.Message{ID: "button_download", Other: "Download"}, nil), IsConfirmButton: true},
+	}
+	options.StartInMultiSelectMode = true
+	options.StatusBar = StatusBar()
+	options.SmallTitle = true
+
+	result, err := gaba.List(options)
+	if err != nil {
+		if !errors.Is(err, gaba.ErrCancelled) {
+			gaba.GetLogger().Error("Storage check selection error", "error", err)
+		}
+		return nil, err
+	}
+
+	chosen := make([]cache.QueuedDownload, 0, len(result.Selected))
+	for _, idx := range result.Selected {
+		if idx >= 0 && idx < len(planned) {
+			chosen = append(chosen, result.Items[idx].Metadata.(cache.QueuedDownload))
+		}
+	}
+
+	return chosen, nil
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"errors"
+	"grout/cache"
+	"grout/download"
+	"grout/internal"
+	"grout/internal/stringutil"
+	"strings"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type SubscriptionsScreen struct{}
+
+func NewSubscriptionsScreen() *SubscriptionsScreen {
+	return &SubscriptionsScreen{}
+}
+
+// Execute fetches the subscribed collections, shows what syncing them would download and
+// remove, and applies it once confirmed. It reports whether anything was changed.
+func (s *SubscriptionsScreen) Execute(config *internal.Config, queue *download.Queue) bool {
+	logger := gaba.GetLogger()
+
+	var plan download.SubscriptionPlan
+	var planErr error
+	gaba.ProcessMessage(
+		i18n.Localize(&goi18n.Message{ID: "subscriptions_checking", Other: "Checking subscriptions..."}, nil),
+		gaba.ProcessMessageOptions{ShowThemeBackground: true},
+		func() (interface{}, error) {
+			if len(config.Subscriptions) > 0 {
+				if err := cache.GetCacheManager().RefreshCollections(); err != nil {
+					logger.Warn("Unable to refresh collections", "error", err)
+				}
+			}
+			plan, planErr = download.PlanSubscriptions(*config, queue.Items())
+			return nil, nil
+		},
+	)
+
+	if planErr != nil {
+		logger.Error("Unable to plan subscriptions", "error", planErr)
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "subscriptions_unavailable", Other: "Subscribed collections could not be loaded.\nRefresh the cache and try again."}, nil),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+		return false
+	}
+
+	if plan.IsEmpty() {
+		message := i18n.Localize(&goi18n.Message{ID: "subscriptions_up_to_date", Other: "Subscribed games are up to date."}, nil)
+		if plan.OverCap > 0 {
+			message += "\n" + s.overCap(plan.OverCap)
+		}
+		gaba.ConfirmationMessage(message, ContinueFooter(), gaba.MessageOptions{})
+		return false
+	}
+
+	_, err := gaba.ConfirmationMessage(
+		s.summary(plan),
+		[]gaba.FooterHelpItem{
+			FooterCancel(),
+			footerItem("A", "button_sync", "Sync"),
+		},
+		gaba.MessageOptions{},
+	)
+	if err != nil {
+		if !errors.Is(err, gaba.ErrCancelled) {
+			logger.Error("Subscriptions confirmation error", "error", err)
+		}
+		return false
+	}
+
+	var result download.SubscriptionResult
+	var applyErr error
+	gaba.ProcessMessage(
+		i18n.Localize(&goi18n.Message{ID: "subscriptions_syncing", Other: "Syncing subscriptions..."}, nil),
+		gaba.ProcessMessageOptions{ShowThemeBackground: true},
+		func() (interface{}, error) {
+			result, applyErr = queue.ApplySubscriptions(plan)
+			return nil, nil
+		},
+	)
+
+	switch {
+	case applyErr != nil:
+		logger.Error("Subscription sync finished with errors", "error", applyErr)
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "subscriptions_failed", Other: "Some games could not be removed or their saves uploaded.\nThey will be tried again on the next sync."}, nil),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+	case result.NoRoom > 0:
+		gaba.ConfirmationMessage(
+			i18n.Localize(&goi18n.Message{ID: "subscriptions_no_room", Other: "{{.Count}} games did not fit on the SD card and were not queued."},
+				map[string]interface{}{"Count": result.NoRoom}),
+			ContinueFooter(),
+			gaba.MessageOptions{},
+		)
+	}
+
+	return result.Queued > 0 || result.Removed > 0
+}
+
+func (s *SubscriptionsScreen) summary(plan download.SubscriptionPlan) string {
+	var lines []string
+	if len(plan.Add) > 0 {
+		lines = append(lines, i18n.Localize(&goi18n.Message{ID: "subscriptions_add", Other: "Download {{.Count}} games ({{.Size}})"},
+			map[string]interface{}{"Count": len(plan.Add), "Size": stringutil.FormatBytes(plan.AddSize)}))
+	}
+	if len(plan.Remove) > 0 {
+		lines = append(lines, i18n.Localize(&goi18n.Message{ID: "subscriptions_remove", Other: "Remove {{.Count}} games no longer subscribed to\n(their saves are uploaded and kept)"},
+			map[string]interface{}{"Count": len(plan.Remove)}))
+	}
+	if plan.OverCap > 0 {
+		lines = append(lines, s.overCap(plan.OverCap))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *SubscriptionsScreen) overCap(count int) string {
+	return i18n.Localize(&goi18n.Message{ID: "subscriptions_over_cap", Other: "{{.Count}} games are over the storage cap"},
+		map[string]interface{}{"Count": count})
+}
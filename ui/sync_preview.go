@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"grout/internal/stringutil"
+	"grout/sync"
+	"slices"
+	"strings"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	icons "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/constants"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type syncPreviewInput struct {
+	Syncs []sync.SaveSync
+}
+
+type syncPreviewOutput struct {
+	Selected   []sync.SaveSync
+	Deselected []sync.SaveSync
+}
+
+type SyncPreviewScreen struct{}
+
+func newSyncPreviewScreen() *SyncPreviewScreen {
+	return &SyncPreviewScreen{}
+}
+
+type syncPreviewFilter int
+
+const (
+	syncPreviewAll syncPreviewFilter = iota
+	syncPreviewUploadsOnly
+	syncPreviewDownloadsOnly
+)
+
+// draw lists every planned upload, download and skip. Nothing touches disk or the server
+// until the user confirms, and individual items can be deselected first.
+func (s *SyncPreviewScreen) draw(input syncPreviewInput) (ScreenResult[syncPreviewOutput], error) {
+	output := syncPreviewOutput{}
+
+	syncs := slices.Clone(input.Syncs)
+	slices.SortFunc(syncs, func(a, b sync.SaveSync) int {
+		if c := syncActionOrder(a.Action) - syncActionOrder(b.Action); c != 0 {
+			return c
+		}
+		return strings.Compare(a.GameBase, b.GameBase)
+	})
+
+	selected := make([]bool, len(syncs))
+	applySyncPreviewFilter(syncs, selected, syncPreviewAll)
+
+	selectedIndex := 0
+	visibleStartIndex := 0
+
+	for {
+		items := make([]gaba.MenuItem, len(syncs))
+		for i, ss := range syncs {
+			items[i] = gaba.MenuItem{
+				Text:               syncPreviewText(ss),
+				Selected:           selected[i],
+				NotMultiSelectable: ss.Action == sync.Skip,
+			}
+		}
+
+		options := gaba.DefaultListOptions(
+			i18n.Localize(&goi18n.Message{ID: "save_sync_preview_title", Other: "Sync Plan"}, nil),
+			items,
+		)
+		options.FooterHelpItems = []gaba.FooterHelpItem{
+			FooterCancel(),
+			footerItem("X", "save_sync_preview_uploads_only", "Uploads Only"),
+			footerItem("Y", "save_sync_preview_downloads_only", "Downloads Only"),
+			{ButtonName: icons.Start, HelpText: i18n.Localize(&goi18n.Message{ID: "button_save_sync", Other: "Sync"}, nil), IsConfirmButton: true},
+		}
+		options.ActionButton = icons.VirtualButtonX
+		options.SecondaryActionButton = icons.VirtualButtonY
+		options.StartInMultiSelectMode = true
+		options.SelectedIndex = selectedIndex
+		options.VisibleStartIndex = visibleStartIndex
+		options.StatusBar = StatusBar()
+		options.SmallTitle = true
+
+		result, err := gaba.List(options)
+		if err != nil {
+			if errors.Is(err, gaba.ErrCancelled) {
+				return back(output), nil
+			}
+			gaba.GetLogger().Error("Sync preview error", "error", err)
+			return withCode(output, gaba.ExitCodeError), err
+		}
+
+		// Selected holds the checked items; the cursor is the focused one
+		if focused := slices.IndexFunc(result.Items, func(item gaba.MenuItem) bool { return item.Focused }); focused >= 0 {
+			selectedIndex = focused
+		}
+		if len(result.Selected) > 0 {
+			visibleStartIndex = max(0, result.Selected[0]-result.VisiblePosition)
+		}
+
+		switch result.Action {
+		case gaba.ListActionTriggered:
+			applySyncPreviewFilter(syncs, selected, syncPreviewUploadsOnly)
+			continue
+		case gaba.ListActionSecondaryTriggered:
+			applySyncPreviewFilter(syncs, selected, syncPreviewDownloadsOnly)
+			continue
+		}
+
+		chosen := make(map[int]bool, len(result.Selected))
+		for _, idx := range result.Selected {
+			chosen[idx] = true
+		}
+
+		for i, ss := range syncs {
+			if ss.Action == sync.Skip {
+				continue
+			}
+			if chosen[i] {
+				output.Selected = append(output.Selected, ss)
+			} else {
+				output.Deselected = append(output.Deselected, ss)
+			}
+		}
+
+		return success(output), nil
+	}
+}
+
+func applySyncPreviewFilter(syncs []sync.SaveSync, selected []bool, filter syncPreviewFilter) {
+	for i, ss := range syncs {
+		switch filter {
+		case syncPreviewUploadsOnly:
+			selected[i] = ss.Action == sync.Upload
+		case syncPreviewDownloadsOnly:
+			selected[i] = ss.Action == sync.Download
+		default:
+			selected[i] = ss.Action != sync.Skip
+		}
+	}
+}
+
+func syncActionOrder(action sync.SyncAction) int {
+	switch action {
+	case sync.Upload:
+		return 0
+	case sync.Download:
+		return 1
+	case sync.Conflict:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func syncPreviewText(ss sync.SaveSync) string {
+	name := ss.GameBase
+	reason := syncReasonText(ss.Reason)
+
+	switch ss.Action {
+	case sync.Upload:
+		return fmt.Sprintf("%s %s · %s · %s", icons.CloudUpload, name, stringutil.FormatBytes(ss.Size()), reason)
+	case sync.Download:
+		return fmt.Sprintf("%s %s · %s · %s", icons.CloudDownload, name, stringutil.FormatBytes(ss.Size()), reason)
+	case sync.Conflict:
+		return fmt.Sprintf("%s %s · %s", icons.CloudAlert, name, reason)
+	default:
+		return fmt.Sprintf("%s %s · %s", icons.CloudCheck, name, reason)
+	}
+}
+
+func syncReasonText(reason sync.SyncReason) string {
+	switch reason {
+	case sync.ReasonOnlyLocal:
+		return i18n.Localize(&goi18n.Message{ID: "save_sync_reason_only_local", Other: "Not on RomM yet"}, nil)
+	case sync.ReasonOnlyRemote:
+		return i18n.Localize(&goi18n.Message{ID: "save_sync_reason_only_remote", Other: "Not on this device"}, nil)
+	case sync.ReasonLocalChanged:
+		return i18n.Localize(&goi18n.Message{ID: "save_sync_reason_local_changed", Other: "Changed on this device"}, nil)
+	case sync.ReasonRemoteChanged:
+		return i18n.Localize(&goi18n.Message{ID: "save_sync_reason_remote_changed", Other: "Newer save on RomM"}, nil)
+	case sync.ReasonLocalNewer:
+		return i18n.Localize(&goi18n.Message{ID: "save_sync_reason_local_newer", Other: "Local save is newer"}, nil)
+	case sync.ReasonRemoteNewer:
+		return i18n.Localize(&goi18n.Message{ID: "save_sync_reason_remote_newer", Other: "RomM save is newer"}, nil)
+	case sync.ReasonExcludedGame:
+		return i18n.Localize(&goi18n.Message{ID: "save_sync_reason_excluded_game", Other: "Game excluded from sync"}, nil)
+	case sync.ReasonExcludedPlatform:
+		return i18n.Localize(&goi18n.Message{ID: "save_sync_reason_excluded_platform", Other: "Platform excluded from sync"}, nil)
+	case sync.ReasonBlankCard:
+		return i18n.Localize(&goi18n.Message{ID: "save_sync_reason_blank_card", Other: "Blank card on this device"}, nil)
+	case sync.ReasonCardConflict:
+		return i18n.Localize(&goi18n.Message{ID: "save_sync_reason_card_conflict", Other: "Changed on both sides"}, nil)
+	case sync.ReasonUnchanged, sync.ReasonSameTimestamp:
+		return i18n.Localize(&goi18n.Message{ID: "save_sync_reason_up_to_date", Other: "Up to date"}, nil)
+	default:
+		return ""
+	}
+}
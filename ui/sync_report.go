@@ -3,8 +3,10 @@ package ui
 import (
 	"errors"
 	"fmt"
+	"grout/cache"
 	"grout/sync"
 	"path/filepath"
+	"strings"
 
 	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
 	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
@@ -12,8 +14,10 @@ import (
 )
 
 type syncReportInput struct {
-	Results   []sync.SyncResult
-	Unmatched []sync.UnmatchedSave
+	Results    []sync.SyncResult
+	Unmatched  []sync.UnmatchedSave
+	Queued     []cache.PendingSync
+	DeviceName string
 }
 
 type syncReportOutput struct{}
@@ -28,7 +32,10 @@ func (s *SyncReportScreen) draw(input syncReportInput) (ScreenResult[syncReportO
 	logger := gaba.GetLogger()
 	output := syncReportOutput{}
 
-	sections := s.buildSections(input.Results, input.Unmatched)
+	sections := s.buildSections(input.Results, input.Unmatched, input.DeviceName)
+	if queued := buildQueuedSection(input.Queued); queued != nil {
+		sections = append(sections, *queued)
+	}
 
 	options := gaba.DefaultInfoScreenOptions()
 	options.Sections = sections
@@ -54,7 +61,7 @@ func (s *SyncReportScreen) draw(input syncReportInput) (ScreenResult[syncReportO
 	return success(output), nil
 }
 
-func (s *SyncReportScreen) buildSections(results []sync.SyncResult, unmatched []sync.UnmatchedSave) []gaba.Section {
+func (s *SyncReportScreen) buildSections(results []sync.SyncResult, unmatched []sync.UnmatchedSave, deviceName string) []gaba.Section {
 	logger := gaba.GetLogger()
 	logger.Debug("Building sync report", "totalResults", len(results), "unmatched", len(unmatched))
 
@@ -63,6 +70,7 @@ func (s *SyncReportScreen) buildSections(results []sync.SyncResult, unmatched []
 	uploadedCount := 0
 	downloadedCount := 0
 	skippedCount := 0
+	conflictCount := 0
 	failedCount := 0
 
 	for _, r := range results {
@@ -75,6 +83,8 @@ func (s *SyncReportScreen) buildSections(results []sync.SyncResult, unmatched []
 			uploadedCount++
 		case sync.Download:
 			downloadedCount++
+		case sync.Conflict:
+			conflictCount++
 		case sync.Skip:
 			skippedCount++
 		}
@@ -84,6 +94,11 @@ func (s *SyncReportScreen) buildSections(results []sync.SyncResult, unmatched []
 		{Label: i18n.Localize(&goi18n.Message{ID: "save_sync_total_processed", Other: "Total Processed"}, nil), Value: fmt.Sprintf("%d", len(results))},
 	}
 
+	if deviceName != "" {
+		summary = append(summary, gaba.MetadataItem{
+			Label: i18n.Localize(&goi18n.Message{ID: "save_sync_this_device", Other: "This Device"}, nil), Value: deviceName})
+	}
+
 	if downloadedCount > 0 {
 		summary = append(summary, gaba.MetadataItem{Label: i18n.Localize(&goi18n.Message{ID: "save_sync_downloaded", Other: "Downloaded"}, nil), Value: fmt.Sprintf("%d", downloadedCount)})
 	}
@@ -116,6 +131,9 @@ func (s *SyncReportScreen) buildSections(results []sync.SyncResult, unmatched []
 				if displayName == "" {
 					displayName = filepath.Base(r.FilePath)
 				}
+				if r.Device != "" {
+					displayName = i18n.Localize(&goi18n.Message{ID: "save_sync_from_device", Other: "{{.Name}} (from {{.Device}})"}, map[string]interface{}{"Name": displayName, "Device": r.Device})
+				}
 				downloadedFiles += displayName
 			}
 		}
@@ -144,6 +162,21 @@ func (s *SyncReportScreen) buildSections(results []sync.SyncResult, unmatched []
 		sections = append(sections, gaba.NewDescriptionSection(i18n.Localize(&goi18n.Message{ID: "save_sync_uploaded", Other: "Uploaded"}, nil), uploadedFiles))
 	}
 
+	// Shared cards changed on both sides: the local card was kept and the RomM copy set aside
+	if conflictCount > 0 {
+		conflicts := ""
+		for _, r := range results {
+			if r.Success && r.Action == sync.Conflict {
+				if conflicts != "" {
+					conflicts += "\n"
+				}
+				conflicts += i18n.Localize(&goi18n.Message{ID: "save_sync_card_conflict_kept", Other: "{{.Name}}: kept this device's card, RomM copy saved to {{.Path}}"},
+					map[string]interface{}{"Name": r.GameName, "Path": filepath.Base(r.FilePath)})
+			}
+		}
+		sections = append(sections, gaba.NewDescriptionSection(i18n.Localize(&goi18n.Message{ID: "save_sync_card_conflicts", Other: "Memory Card Conflicts"}, nil), conflicts))
+	}
+
 	if failedCount > 0 {
 		failedFiles := ""
 		for _, r := range results {
@@ -165,6 +198,25 @@ func (s *SyncReportScreen) buildSections(results []sync.SyncResult, unmatched []
 		sections = append(sections, gaba.NewDescriptionSection(i18n.Localize(&goi18n.Message{ID: "save_sync_failed", Other: "Failed"}, nil), failedFiles))
 	}
 
+	// Games the user excluded from sync, so they aren't mistaken for failures
+	excludedText := ""
+	for _, r := range results {
+		if !r.Reason.Excluded() {
+			continue
+		}
+		if excludedText != "" {
+			excludedText += "\n"
+		}
+		displayName := r.RomDisplayName
+		if displayName == "" {
+			displayName = r.GameName
+		}
+		excludedText += fmt.Sprintf("%s: %s", displayName, syncReasonText(r.Reason))
+	}
+	if excludedText != "" {
+		sections = append(sections, gaba.NewDescriptionSection(i18n.Localize(&goi18n.Message{ID: "save_sync_excluded", Other: "Excluded from Sync"}, nil), excludedText))
+	}
+
 	// Display unmatched saves (ROM not found in RomM)
 	if len(unmatched) > 0 {
 		unmatchedText := ""
@@ -179,3 +231,32 @@ func (s *SyncReportScreen) buildSections(results []sync.SyncResult, unmatched []
 
 	return sections
 }
+
+// buildQueuedSection lists syncs waiting in the retry queue along with why they last failed.
+func buildQueuedSection(queued []cache.PendingSync) *gaba.Section {
+	if len(queued) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(queued))
+	for _, p := range queued {
+		name := p.RomName
+		if name == "" {
+			name = p.GameBase
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+
+		errorMsg := p.LastError
+		if errorMsg == "" {
+			errorMsg = i18n.Localize(&goi18n.Message{ID: "save_sync_unknown_error", Other: "Unknown error"}, nil)
+		}
+
+		lines = append(lines, fmt.Sprintf("%s (%s): %s", name, p.Action, errorMsg))
+	}
+
+	section := gaba.NewDescriptionSection(
+		i18n.Localize(&goi18n.Message{ID: "save_sync_queued", Other: "Waiting to Retry"}, nil),
+		strings.Join(lines, "\n"),
+	)
+	return &section
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"errors"
+	"grout/romm"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type UpdateGamesScreen struct{}
+
+func NewUpdateGamesScreen() *UpdateGamesScreen {
+	return &UpdateGamesScreen{}
+}
+
+// Execute confirms downloading the new versions of games that changed on RomM.
+func (s *UpdateGamesScreen) Execute(games []romm.Rom) bool {
+	if len(games) == 0 {
+		return false
+	}
+
+	var message string
+	if len(games) == 1 {
+		message = i18n.Localize(&goi18n.Message{ID: "update_games_confirm_one", Other: "{{.Name}} has changed on RomM.\nDownload the new version? Its saves are kept."},
+			map[string]interface{}{"Name": games[0].Name})
+	} else {
+		message = i18n.Localize(&goi18n.Message{ID: "update_games_confirm", Other: "{{.Count}} games have changed on RomM.\nDownload the new versions? Their saves are kept."},
+			map[string]interface{}{"Count": len(games)})
+	}
+
+	_, err := gaba.ConfirmationMessage(
+		message,
+		[]gaba.FooterHelpItem{
+			FooterCancel(),
+			footerItem("A", "button_update", "Update"),
+		},
+		gaba.MessageOptions{},
+	)
+	if err != nil {
+		if !errors.Is(err, gaba.ErrCancelled) {
+			gaba.GetLogger().Error("Update games confirmation error", "error", err)
+		}
+		return false
+	}
+
+	return true
+}
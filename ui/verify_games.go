@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"grout/download"
+	"grout/internal"
+	"strings"
+
+	gaba "github.com/BrandonKowalski/gabagool/v2/pkg/gabagool"
+	"github.com/BrandonKowalski/gabagool/v2/pkg/gabagool/i18n"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"go.uber.org/atomic"
+)
+
+type VerifyGamesOutput struct{}
+
+type VerifyGamesScreen struct{}
+
+func NewVerifyGamesScreen() *VerifyGamesScreen {
+	return &VerifyGamesScreen{}
+}
+
+// Execute checks the installed games against RomM's checksums and lists any that are
+// damaged, so they can be downloaded again.
+func (s *VerifyGamesScreen) Execute(config internal.Config) VerifyGamesOutput {
+	var result download.AuditResult
+	var err error
+	progress := &atomic.Float64{}
+	gaba.ProcessMessage(
+		i18n.Localize(&goi18n.Message{ID: "verify_games_working", Other: "Verifying games..."}, nil),
+		gaba.ProcessMessageOptions{ShowThemeBackground: true, ShowProgressBar: true, Progress: progress},
+		func() (interface{}, error) {
+			result, err = download.AuditInstalled(config, progress)
+			return nil, nil
+		},
+	)
+
+	if err != nil {
+		gaba.GetLogger().Error("Unable to verify every game", "error", err)
+	}
+
+	var message string
+	switch {
+	case len(result.Damaged) > 0:
+		names := make([]string, len(result.Damaged))
+		for i, game := range result.Damaged {
+			names[i] = game.Name
+		}
+		message = i18n.Localize(&goi18n.Message{ID: "verify_games_damaged", Other: "{{.Count}} games are damaged and should be downloaded again:\n{{.Names}}"},
+			map[string]interface{}{"Count": len(result.Damaged), "Names": strings.Join(names, "\n")})
+	case err != nil:
+		message = i18n.Localize(&goi18n.Message{ID: "verify_games_failed", Other: "Some games could not be verified.\nCheck the log for details."}, nil)
+	case result.Checked == 0:
+		message = i18n.Localize(&goi18n.Message{ID: "verify_games_none", Other: "There are no downloaded games to verify."}, nil)
+	default:
+		message = i18n.Localize(&goi18n.Message{ID: "verify_games_done", Other: "All {{.Count}} games match RomM."},
+			map[string]interface{}{"Count": result.Checked})
+	}
+
+	gaba.ConfirmationMessage(message, ContinueFooter(), gaba.MessageOptions{})
+	return VerifyGamesOutput{}
+}